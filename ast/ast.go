@@ -55,6 +55,20 @@ func (s String) String() string {
 	return fmt.Sprintf("'%s'", s.Value)
 }
 
+// InterpolatedString is a string with `#{...}` in it, `"n is #{$n}"`. Its
+// parts are the literal text, as Strings, and the interpolated expressions
+// in source order.
+type InterpolatedString struct {
+	Parts []Value
+	Span  lexer.Span
+}
+
+func (s InterpolatedString) IsValue() {}
+
+func (s InterpolatedString) GetSpan() lexer.Span {
+	return s.Span
+}
+
 type Int struct {
 	Value int64
 	Span  lexer.Span
@@ -100,6 +114,44 @@ func (b Boolean) String() string {
 	return fmt.Sprintf("Bool(%t)", b.Value)
 }
 
+// List is both the `(1, 2, 3)` / `[1 2 3]` literal and the runtime list value
+type List struct {
+	Values []Value
+	Span   lexer.Span
+}
+
+func (l List) IsValue() {}
+
+func (l List) GetSpan() lexer.Span {
+	return l.Span
+}
+
+func (l List) String() string {
+	return fmt.Sprintf("List%v", l.Values)
+}
+
+type MapEntry struct {
+	Key   Value
+	Value Value
+}
+
+// Map is both the `(key: value)` literal and the runtime map value, entries
+// keep the order they were written in.
+type Map struct {
+	Entries []MapEntry
+	Span    lexer.Span
+}
+
+func (m Map) IsValue() {}
+
+func (m Map) GetSpan() lexer.Span {
+	return m.Span
+}
+
+func (m Map) String() string {
+	return fmt.Sprintf("Map%v", m.Entries)
+}
+
 type VarianleDerefValue struct {
 	Variable Identifier
 	Span     lexer.Span
@@ -123,9 +175,13 @@ type Statement interface {
 type Declaration struct {
 	Property   Identifier
 	Parameters []Value
-	Span       lexer.Span
+	// Important is set for `--x: 5 !important;` which declares a constant
+	Important bool
+	Span      lexer.Span
 }
 
+func (d Declaration) isRule() {}
+
 func (d Declaration) IsStatement() {}
 
 func (d Declaration) GetSpan() lexer.Span {
@@ -176,8 +232,11 @@ func (b BinaryOp) GetSpan() lexer.Span {
 type AtRule struct {
 	Name       string
 	Parameters []Value
-	Body       []Statement
-	Span       lexer.Span
+	// Selector is the prelude of at-rules that take a selector instead of
+	// parameters, like `@type point[x][y];`
+	Selector *Selector
+	Body     []Statement
+	Span     lexer.Span
 }
 
 func (r AtRule) isRule() {}
@@ -191,13 +250,112 @@ func (r AtRule) GetSpan() lexer.Span {
 type Attreibute struct {
 	Name    Identifier
 	Default Value
-	Span    lexer.Span
+	// Type is the optional type annotation of `[n:int]`, its name is empty
+	// when the attribute is untyped.
+	Type Identifier
+	// Operator and Operand describe a css attribute constraint such as
+	// `[s^="http"]`, Operator is empty when there is none.
+	Operator string
+	Operand  Value
+	Span     lexer.Span
+}
+
+func (a Attreibute) IsConstrained() bool {
+	return a.Type.Name != "" || a.Operator != ""
+}
+
+// Constraint returns the type annotation and operator of the attribute as
+// written, e.g. `:string^='http'`.
+func (a Attreibute) Constraint() string {
+	str := ""
+	if a.Type.Name != "" {
+		str += ":" + a.Type.Name
+	}
+	if a.Operator != "" {
+		str += fmt.Sprintf("%s%v", a.Operator, a.Operand)
+	}
+	return str
+}
+
+const (
+	COMBINATOR_DESCENDANT = " "
+	COMBINATOR_CHILD      = ">"
+)
+
+// SelectorSegment is one compound selector of a complex selector along with
+// the combinator that joins it to the segment on its right.
+type SelectorSegment struct {
+	Name       Identifier
+	Combinator string
+}
+
+// PseudoClass is a guard like `:where($n <= 1)` attached to a selector.
+type PseudoClass struct {
+	Name     Identifier
+	Argument Value
+	Span     lexer.Span
 }
 
 type Selector struct {
-	Identifier Identifier
-	Atrributes []Attreibute
-	Span       lexer.Span
+	// Path holds the namespaces the rule lives in, `math > sqrt[x]` has a
+	// path of `math >` and the identifier `sqrt`.
+	Path          []SelectorSegment
+	Identifier    Identifier
+	Atrributes    []Attreibute
+	PseudoClasses []PseudoClass
+	Span          lexer.Span
+}
+
+// PathString returns the selector without its attributes, e.g. `math > sqrt`.
+func (s Selector) PathString() string {
+	str := ""
+	for _, seg := range s.Path {
+		if seg.Combinator == COMBINATOR_CHILD {
+			str += seg.Name.Name + " > "
+		} else {
+			str += seg.Name.Name + " "
+		}
+	}
+	return str + s.Identifier.Name
+}
+
+// Specificity ranks selectors the way the css cascade does. Guards count the
+// most, then constrained attributes and finally the depth of the namespace
+// path.
+type Specificity [3]int
+
+// Compare returns 1 if s is more specific than other, -1 if it is less
+// specific and 0 when both are equally specific.
+func (s Specificity) Compare(other Specificity) int {
+	for i := range s {
+		if s[i] > other[i] {
+			return 1
+		}
+		if s[i] < other[i] {
+			return -1
+		}
+	}
+	return 0
+}
+
+func (s Selector) Specificity() Specificity {
+	constrained := 0
+	for _, attr := range s.Atrributes {
+		if attr.IsConstrained() {
+			constrained++
+		}
+	}
+	return Specificity{len(s.PseudoClasses), constrained, len(s.Path)}
+}
+
+// Signature identifies the calls a selector accepts, ignoring guards. Two
+// unguarded rules with the same signature can never be told apart.
+func (s Selector) Signature() string {
+	sig := s.PathString()
+	for _, attr := range s.Atrributes {
+		sig += "[" + attr.Constraint() + "]"
+	}
+	return sig
 }
 
 type IRule interface {
@@ -218,6 +376,33 @@ func (r Rule) GetSpan() lexer.Span {
 	return r.Span
 }
 
+// AnonymousRule is a function literal, `&[x] { @return $x * 2; }`. Its
+// selector is named `&` like the parent selector of css nesting.
+type AnonymousRule struct {
+	Selector Selector
+	Body     []Statement
+	Span     lexer.Span
+}
+
+func (a AnonymousRule) IsValue() {}
+
+func (a AnonymousRule) GetSpan() lexer.Span {
+	return a.Span
+}
+
+// Match is a `@match` rule used as a value, `--sign: @match $n { ... };`
+// evaluates to the value `@return`ed by the case that matched.
+type Match struct {
+	Rule AtRule
+	Span lexer.Span
+}
+
+func (m Match) IsValue() {}
+
+func (m Match) GetSpan() lexer.Span {
+	return m.Span
+}
+
 type Program struct {
 	Rules []IRule
 }
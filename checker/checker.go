@@ -0,0 +1,323 @@
+package checker
+
+import (
+	"strings"
+
+	"github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/diagnostic"
+)
+
+// Checker looks for mistakes that are legal syntax but can never do what the
+// author meant, before the program is run.
+type Checker struct {
+	diagnostics []diagnostic.Diagnostic
+}
+
+// scope tracks the constants and enums visible in a block of the program.
+type scope struct {
+	parent    *scope
+	important map[string]ast.Declaration
+	// enums maps the name of an enum to its variants, in declaration order
+	enums map[string][]string
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{
+		parent:    parent,
+		important: make(map[string]ast.Declaration),
+		enums:     make(map[string][]string),
+	}
+}
+
+func (s *scope) lookupEnum(name string) ([]string, bool) {
+	variants, ok := s.enums[name]
+	if !ok && s.parent != nil {
+		return s.parent.lookupEnum(name)
+	}
+	return variants, ok
+}
+
+func (s *scope) lookupImportant(name string) (ast.Declaration, bool) {
+	decl, ok := s.important[name]
+	if !ok && s.parent != nil {
+		return s.parent.lookupImportant(name)
+	}
+	return decl, ok
+}
+
+func Check(program ast.Program) []diagnostic.Diagnostic {
+	c := &Checker{}
+
+	stmts := []ast.Statement{}
+	for _, rule := range program.Rules {
+		if stmt, ok := rule.(ast.Statement); ok {
+			stmts = append(stmts, stmt)
+		}
+	}
+	c.checkScope(stmts, newScope(nil))
+
+	return c.diagnostics
+}
+
+func (c *Checker) report(diag diagnostic.Diagnostic) {
+	c.diagnostics = append(c.diagnostics, diag)
+}
+
+func isVariable(name string) bool {
+	return len(name) > 2 && name[:2] == "--"
+}
+
+// checkScope checks the statements of one block, and recursively the blocks
+// nested inside it.
+func (c *Checker) checkScope(stmts []ast.Statement, sc *scope) {
+	c.checkOverloads(stmts)
+
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case ast.Rule:
+			c.checkRule(stmt, sc)
+		case ast.AtRule:
+			c.checkValues(stmt.Parameters, sc)
+			switch stmt.Name {
+			case "const", "config":
+				// Their variables are constants of the enclosing scope
+				c.checkConstants(stmt.Body, sc)
+				continue
+			case "enum":
+				if stmt.Selector != nil {
+					variants := []string{}
+					for _, attr := range stmt.Selector.Atrributes {
+						variants = append(variants, attr.Name.Name)
+					}
+					sc.enums[stmt.Selector.Identifier.Name] = variants
+				}
+			case "match":
+				c.checkMatch(stmt, sc)
+			}
+			c.checkScope(stmt.Body, newScope(sc))
+		case ast.Declaration:
+			c.checkValues(stmt.Parameters, sc)
+			c.checkDeclaration(stmt, sc)
+		}
+	}
+}
+
+func (c *Checker) checkRule(rule ast.Rule, sc *scope) {
+	for _, attr := range rule.Selector.Atrributes {
+		decl, ok := sc.lookupImportant(attr.Name.Name)
+		if !ok {
+			continue
+		}
+
+		start := decl.Span.Start
+		c.report(diagnostic.Warningf(attr.Span, "parameter %s shadows the important variable %s", attr.Name.Name, decl.Property.Name).
+			WithNote("%s is declared at %d:%d", decl.Property.Name, start.Line, start.Col))
+	}
+
+	c.checkScope(rule.Body, newScope(sc))
+}
+
+func (c *Checker) checkDeclaration(decl ast.Declaration, sc *scope) {
+	if !isVariable(decl.Property.Name) {
+		if decl.Important {
+			c.report(diagnostic.Errorf(decl.Span, "!important can only be used on variable declarations"))
+		}
+		return
+	}
+
+	name := decl.Property.Name[2:]
+	if prev, ok := sc.lookupImportant(name); ok {
+		start := prev.Span.Start
+		c.report(diagnostic.Errorf(decl.Span, "cannot reassign %s, it was declared !important", decl.Property.Name).
+			WithNote("%s is declared at %d:%d", prev.Property.Name, start.Line, start.Col))
+		return
+	}
+
+	if decl.Important {
+		sc.important[name] = decl
+	}
+}
+
+func (c *Checker) checkConstants(stmts []ast.Statement, sc *scope) {
+	for _, stmt := range stmts {
+		decl, ok := stmt.(ast.Declaration)
+		if !ok || !isVariable(decl.Property.Name) {
+			continue
+		}
+
+		decl.Important = true
+		c.checkValues(decl.Parameters, sc)
+		c.checkDeclaration(decl, sc)
+	}
+}
+
+// checkOverloads warns about rules that can never be dispatched to because an
+// earlier rule in the same scope accepts exactly the same calls.
+func (c *Checker) checkOverloads(stmts []ast.Statement) {
+	seen := map[string]ast.Rule{}
+	for _, stmt := range stmts {
+		rule, ok := stmt.(ast.Rule)
+		if !ok || len(rule.Selector.PseudoClasses) > 0 {
+			continue
+		}
+
+		signature := rule.Selector.Signature()
+		first, ok := seen[signature]
+		if !ok {
+			seen[signature] = rule
+			continue
+		}
+
+		start := first.Selector.Span.Start
+		c.report(diagnostic.Warningf(rule.Selector.Span, "ambiguous overload of %s, this rule is never called", rule.Selector.PathString()).
+			WithNote("%s is already defined at %d:%d and always takes precedence", first.Selector.PathString(), start.Line, start.Col))
+	}
+}
+
+// checkValues looks for `@match` rules used as values.
+func (c *Checker) checkValues(values []ast.Value, sc *scope) {
+	for _, value := range values {
+		switch value := value.(type) {
+		case ast.Match:
+			c.checkValues(value.Rule.Parameters, sc)
+			c.checkMatch(value.Rule, sc)
+			c.checkScope(value.Rule.Body, newScope(sc))
+		case ast.List:
+			c.checkValues(value.Values, sc)
+		case ast.InterpolatedString:
+			c.checkValues(value.Parts, sc)
+		case ast.Map:
+			for _, entry := range value.Entries {
+				c.checkValues([]ast.Value{entry.Key, entry.Value}, sc)
+			}
+		case ast.FunctionCall:
+			c.checkValues(value.Parameters, sc)
+		case ast.UnaryOp:
+			c.checkValues([]ast.Value{value.Value}, sc)
+		case ast.BinaryOp:
+			c.checkValues([]ast.Value{value.Left, value.Right}, sc)
+		}
+	}
+}
+
+// isBoolean reports whether value is known to evaluate to a boolean without
+// running it.
+func isBoolean(value ast.Value) bool {
+	switch value := value.(type) {
+	case ast.Boolean:
+		return true
+	case ast.UnaryOp:
+		return value.Op == "!"
+	case ast.BinaryOp:
+		switch value.Op {
+		case "==", "!=", "<", "<=", ">", ">=", "&&", "||":
+			return true
+		}
+	}
+	return false
+}
+
+// isCatchAll reports whether a case pattern accepts a value of any type.
+func isCatchAll(pattern ast.Value) bool {
+	switch pattern := pattern.(type) {
+	case ast.VarianleDerefValue:
+		return !strings.Contains(pattern.Variable.Name, ".")
+	case ast.Identifier:
+		return pattern.Name == "_" || pattern.Name == "any"
+	}
+	return false
+}
+
+// enumVariant splits a pattern like `$direction.north` into the enum and the
+// variant it names, when direction is a known enum.
+func enumVariant(pattern ast.Value, sc *scope) (string, string, bool) {
+	deref, ok := pattern.(ast.VarianleDerefValue)
+	if !ok {
+		return "", "", false
+	}
+	enum, variant, ok := strings.Cut(deref.Variable.Name, ".")
+	if !ok {
+		return "", "", false
+	}
+	_, ok = sc.lookupEnum(enum)
+	return enum, variant, ok
+}
+
+// checkMatch warns about a `@match` over booleans that doesn't handle both
+// true and false, or over a known enum that doesn't handle all of its
+// variants. Guarded cases don't count, they might not match.
+func (c *Checker) checkMatch(rule ast.AtRule, sc *scope) {
+	boolean := len(rule.Parameters) == 1 && isBoolean(rule.Parameters[0])
+	covered := map[bool]bool{}
+	enum := ""
+	coveredVariants := map[string]bool{}
+	for _, stmt := range rule.Body {
+		at, ok := stmt.(ast.AtRule)
+		if !ok || at.Name == "default" {
+			return
+		}
+
+		guarded := false
+		patterns := []ast.Value{}
+		for _, param := range at.Parameters {
+			if id, ok := param.(ast.Identifier); ok && id.Name == "if" {
+				guarded = true
+				break
+			}
+			patterns = append(patterns, param)
+		}
+
+		for _, pattern := range patterns {
+			if id, ok := pattern.(ast.Identifier); ok && id.Name == "bool" && !guarded {
+				return
+			}
+			if isCatchAll(pattern) && !guarded {
+				return
+			}
+			if id, ok := pattern.(ast.Identifier); ok && !guarded {
+				if _, isEnum := sc.lookupEnum(id.Name); isEnum {
+					return
+				}
+			}
+
+			if name, variant, ok := enumVariant(pattern, sc); ok {
+				enum = name
+				if !guarded {
+					coveredVariants[variant] = true
+				}
+				continue
+			}
+
+			b, ok := pattern.(ast.Boolean)
+			if !ok {
+				continue
+			}
+			boolean = true
+			if !guarded {
+				covered[b.Value] = true
+			}
+		}
+	}
+
+	if enum != "" {
+		variants, _ := sc.lookupEnum(enum)
+		for _, variant := range variants {
+			if !coveredVariants[variant] {
+				c.report(diagnostic.Warningf(rule.Span, "non-exhaustive @match, $%s.%s is not handled", enum, variant).
+					WithNote("add a `@case $%s.%s` or a `@default` rule", enum, variant))
+			}
+		}
+		return
+	}
+
+	if !boolean {
+		return
+	}
+
+	for _, value := range []bool{true, false} {
+		if !covered[value] {
+			c.report(diagnostic.Warningf(rule.Span, "non-exhaustive @match, %t is not handled", value).
+				WithNote("add a `@case %t` or a `@default` rule", value))
+		}
+	}
+}
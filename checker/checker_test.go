@@ -0,0 +1,93 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/shreyassanthu77/cisp/lexer"
+	"github.com/shreyassanthu77/cisp/parser"
+)
+
+func check(t *testing.T, src string) []string {
+	t.Helper()
+	program, diags := parser.New(lexer.New(src)).Parse()
+	if len(diags) > 0 {
+		t.Fatalf("unexpected parse error: %s", diags[0].Message)
+	}
+	messages := []string{}
+	for _, diag := range Check(program) {
+		messages = append(messages, diag.Message)
+	}
+	return messages
+}
+
+// messageTest expects checking src to report the messages in want, in order.
+type messageTest struct {
+	name string
+	src  string
+	want []string
+}
+
+func runMessageTests(t *testing.T, tests []messageTest) {
+	t.Helper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := check(t, test.src)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %q, want %q", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("got %q, want %q", got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOverloads(t *testing.T) {
+	runMessageTests(t, []messageTest{
+		{"same signature", `f[x] { } f[y] { }`, []string{"ambiguous overload of f, this rule is never called"}},
+		{"same types", `f[x:int] { } f[y:int] { }`, []string{"ambiguous overload of f, this rule is never called"}},
+		{"different arity", `f[x] { } f[x][y] { }`, nil},
+		{"different types", `f[x:int] { } f[x:string] { }`, nil},
+		{"guards", `f[x]:where($x > 0) { } f[x] { }`, nil},
+		{"different namespaces", `a > f[x] { } b > f[x] { }`, nil},
+		{"nested rules", `main { g[x] { } g[y] { } }`, []string{"ambiguous overload of g, this rule is never called"}},
+	})
+}
+
+func TestImportant(t *testing.T) {
+	runMessageTests(t, []messageTest{
+		{"reassign", `main { --x: 1 !important; --x: 2; }`, []string{"cannot reassign --x, it was declared !important"}},
+		{"reassign a global", `--g: 1 !important; main { --g: 2; }`, []string{"cannot reassign --g, it was declared !important"}},
+		{"reassign in a nested block", `main { --x: 1 !important; @if true { --x: 2; } }`, []string{"cannot reassign --x, it was declared !important"}},
+		{"properties", `main { color: red !important; }`, []string{"!important can only be used on variable declarations"}},
+		{"different rules", `a { --x: 1 !important; } b { --x: 2; }`, nil},
+	})
+}
+
+func TestConstants(t *testing.T) {
+	runMessageTests(t, []messageTest{
+		{"const", `@const --pi: 3; main { --pi: 4; }`, []string{"cannot reassign --pi, it was declared !important"}},
+		{"config", `@config { --debug: true; } --debug: false;`, []string{"cannot reassign --debug, it was declared !important"}},
+		{"plain globals", `--g: 1; main { --g: 2; }`, nil},
+	})
+}
+
+func TestMatchExhaustiveness(t *testing.T) {
+	runMessageTests(t, []messageTest{
+		{"booleans", `main { @match $a == 1 { @case true { } } }`, []string{"non-exhaustive @match, false is not handled"}},
+		{"both booleans", `main { @match $a == 1 { @case true { } @case false { } } }`, nil},
+		{"guarded cases don't count", `main { @match $a == 1 { @case true { } @case false if $b { } } }`, []string{"non-exhaustive @match, false is not handled"}},
+		{"default", `main { @match $a == 1 { @case true { } @default { } } }`, nil},
+		{"catch all", `main { @match $a == 1 { @case true { } @case $x { } } }`, nil},
+		{"enum", `@enum dir[up][down][left]; main { @match $d { @case $dir.up { } } }`, []string{
+			"non-exhaustive @match, $dir.down is not handled",
+			"non-exhaustive @match, $dir.left is not handled",
+		}},
+		{"every variant", `@enum dir[up][down]; main { @match $d { @case $dir.up, $dir.down { } } }`, nil},
+		{"enum type", `@enum dir[up][down]; main { @match $d { @case $dir.up { } @case dir { } } }`, nil},
+		{"unknown enum", `main { @match $d { @case $dir.up { } } }`, nil},
+		{"match as a value", `@enum dir[up][down]; main { --x: @match $d { @case $dir.up { } }; }`, []string{"non-exhaustive @match, $dir.down is not handled"}},
+	})
+}
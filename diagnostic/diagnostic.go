@@ -0,0 +1,73 @@
+package diagnostic
+
+import (
+	"fmt"
+
+	"github.com/shreyassanthu77/cisp/lexer"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Note
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	case Note:
+		return "note"
+	}
+	return "unknown"
+}
+
+type Diagnostic struct {
+	Severity Severity
+	Span     lexer.Span
+	Message  string
+	Notes    []string
+}
+
+func Errorf(span lexer.Span, format string, args ...interface{}) Diagnostic {
+	return Diagnostic{
+		Severity: Error,
+		Span:     span,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func Warningf(span lexer.Span, format string, args ...interface{}) Diagnostic {
+	return Diagnostic{
+		Severity: Warning,
+		Span:     span,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// WithNote returns a copy of the diagnostic with an extra note attached.
+func (d Diagnostic) WithNote(format string, args ...interface{}) Diagnostic {
+	notes := make([]string, len(d.Notes), len(d.Notes)+1)
+	copy(notes, d.Notes)
+	d.Notes = append(notes, fmt.Sprintf(format, args...))
+	return d
+}
+
+func (d Diagnostic) Error() string {
+	line := d.Span.Start.Line
+	col := d.Span.Start.Col
+	return fmt.Sprintf("{%d:%d} %s: %s", line, col, d.Severity, d.Message)
+}
+
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
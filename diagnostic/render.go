@@ -0,0 +1,217 @@
+package diagnostic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shreyassanthu77/cisp/lexer"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorBold   = "\033[1m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorBlue   = "\033[34m"
+	colorCyan   = "\033[36m"
+)
+
+// maxSnippetLines is how many source lines are shown for a multi-line span
+// before the middle is elided.
+const maxSnippetLines = 4
+
+// FromError turns any error into a diagnostic, keeping the span when the
+// error carries one.
+func FromError(err error) Diagnostic {
+	var diag Diagnostic
+	if errors.As(err, &diag) {
+		return diag
+	}
+
+	var diagnoser interface{ Diagnostic() Diagnostic }
+	if errors.As(err, &diagnoser) {
+		return diagnoser.Diagnostic()
+	}
+
+	var lexErr lexer.Error
+	if errors.As(err, &lexErr) {
+		return Diagnostic{
+			Severity: Error,
+			Span:     lexErr.Span,
+			Message:  lexErr.Message,
+		}
+	}
+
+	return Diagnostic{
+		Severity: Error,
+		Message:  err.Error(),
+	}
+}
+
+// Renderer prints diagnostics together with the source lines they point at.
+type Renderer struct {
+	File   string
+	Source string
+	// Sources holds the other files diagnostics can point into, like the
+	// modules a program imports, keyed by path.
+	Sources map[string]string
+	Color   bool
+}
+
+// source returns the file a span points into and its contents.
+func (r Renderer) source(span lexer.Span) (string, string) {
+	if span.File == "" || span.File == r.File {
+		return r.File, r.Source
+	}
+	if src, ok := r.Sources[span.File]; ok {
+		return span.File, src
+	}
+	return r.File, r.Source
+}
+
+func (r Renderer) paint(color, text string) string {
+	if !r.Color {
+		return text
+	}
+	return color + text + colorReset
+}
+
+func (r Renderer) severityColor(s Severity) string {
+	switch s {
+	case Error:
+		return colorRed
+	case Warning:
+		return colorYellow
+	}
+	return colorCyan
+}
+
+func (r Renderer) RenderError(err error) string {
+	return r.Render(FromError(err))
+}
+
+func (r Renderer) Render(d Diagnostic) string {
+	sb := strings.Builder{}
+	color := r.severityColor(d.Severity)
+
+	sb.WriteString(r.paint(colorBold+color, d.Severity.String()))
+	sb.WriteString(r.paint(colorBold, ": "+d.Message))
+	sb.WriteString("\n")
+
+	file, source := r.source(d.Span)
+	hasSpan := d.Span.Start.Line > 0
+	if !hasSpan {
+		if file != "" {
+			sb.WriteString(fmt.Sprintf("  %s %s\n", r.paint(colorBlue, "-->"), file))
+		}
+		r.renderNotes(&sb, d, "  ")
+		return sb.String()
+	}
+
+	lines := snippet(source, d.Span)
+	gutterWidth := len(fmt.Sprint(lines[len(lines)-1].number))
+	pad := strings.Repeat(" ", gutterWidth)
+
+	sb.WriteString(fmt.Sprintf("%s%s %s:%d:%d\n", pad, r.paint(colorBlue, "-->"), file, d.Span.Start.Line, d.Span.Start.Col))
+	sb.WriteString(fmt.Sprintf("%s %s\n", pad, r.paint(colorBlue, "|")))
+
+	for i, line := range lines {
+		if i > 0 && line.number > lines[i-1].number+1 {
+			sb.WriteString(r.paint(colorBlue, "...") + "\n")
+		}
+
+		num := fmt.Sprintf("%*d", gutterWidth, line.number)
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", r.paint(colorBlue, num), r.paint(colorBlue, "|"), line.text))
+
+		underline := indentLike(line.text[:line.start]) + strings.Repeat("^", line.width())
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", pad, r.paint(colorBlue, "|"), r.paint(colorBold+color, underline)))
+	}
+
+	r.renderNotes(&sb, d, pad+" ")
+	return sb.String()
+}
+
+func (r Renderer) renderNotes(sb *strings.Builder, d Diagnostic, pad string) {
+	for _, note := range d.Notes {
+		sb.WriteString(fmt.Sprintf("%s%s %s\n", pad, r.paint(colorBlue, "="), r.paint(colorBold, "note: ")+note))
+	}
+}
+
+type snippetLine struct {
+	number int
+	text   string
+	// start and end are the underlined byte range within text, they can go
+	// past its end to underline the line break
+	start int
+	end   int
+}
+
+// width is the number of characters underlined, at least one.
+func (l snippetLine) width() int {
+	end := min(l.end, len(l.text))
+	n := utf8.RuneCountInString(l.text[l.start:end])
+	// The bytes past the end of the line, like its newline or EOF, count
+	// as one character each
+	n += l.end - end
+	return max(n, 1)
+}
+
+// snippet returns the lines of src covered by span, with the part of each
+// line that should be underlined.
+func snippet(src string, span lexer.Span) []snippetLine {
+	startPos := min(max(span.Start.Pos, 0), len(src))
+	endPos := min(max(span.End.Pos, startPos), len(src))
+
+	lineStart := strings.LastIndexByte(src[:startPos], '\n') + 1
+	number := span.Start.Line
+
+	lines := []snippetLine{}
+	for {
+		lineEnd := strings.IndexByte(src[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(src)
+		} else {
+			lineEnd += lineStart
+		}
+
+		text := strings.TrimRight(src[lineStart:lineEnd], "\r")
+		start := max(startPos-lineStart, 0)
+		end := min(endPos-lineStart, len(text))
+		if end <= start {
+			// Zero width spans, like EOF, still get a single caret
+			end = start + 1
+		}
+
+		lines = append(lines, snippetLine{
+			number: number,
+			text:   text,
+			start:  min(start, len(text)),
+			end:    end,
+		})
+
+		if endPos <= lineEnd+1 || lineEnd >= len(src) {
+			break
+		}
+		lineStart = lineEnd + 1
+		number++
+	}
+
+	if len(lines) > maxSnippetLines {
+		lines = append(lines[:maxSnippetLines-1], lines[len(lines)-1])
+	}
+
+	return lines
+}
+
+// indentLike returns whitespace that lines up with text when printed,
+// keeping tabs so that carets stay aligned with tab indented code.
+func indentLike(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return '\t'
+		}
+		return ' '
+	}, text)
+}
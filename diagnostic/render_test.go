@@ -0,0 +1,31 @@
+package diagnostic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shreyassanthu77/cisp/lexer"
+)
+
+func TestCaretsCountCharacters(t *testing.T) {
+	src := `--x: "日本" + 1;`
+	start := strings.Index(src, `"`)
+	end := strings.LastIndex(src, `"`) + 1
+	d := Diagnostic{
+		Severity: Error,
+		Message:  "oops",
+		Span: lexer.Span{
+			Start: lexer.Loc{Pos: start, Line: 1, Col: 6},
+			End:   lexer.Loc{Pos: end, Line: 1, Col: 10},
+		},
+	}
+
+	out := Renderer{File: "test.css", Source: src}.Render(d)
+	want := "  |      ^^^^\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("got\n%s\nwant it to contain\n%s", out, want)
+	}
+	if !strings.Contains(out, "test.css:1:6") {
+		t.Errorf("got\n%s\nwant the location test.css:1:6", out)
+	}
+}
@@ -94,6 +94,45 @@ func evalAtRule(env *Environment, ifState *IfState, rule ast.AtRule) (ast.Value,
 		return ast.NilValue{}, nil
 	case "return":
 		return evalReturnRule(env, rule)
+	case "for":
+		ifState.reset()
+		return evalForRule(env, rule)
+	case "while":
+		ifState.reset()
+		return evalWhileRule(env, rule)
+	case "each":
+		ifState.reset()
+		return evalEachRule(env, rule)
+	case "type":
+		ifState.reset()
+		return evalTypeRule(env, rule)
+	case "enum":
+		ifState.reset()
+		return evalEnumRule(env, rule)
+	case "const":
+		ifState.reset()
+		return evalStatementList(rule.Body, env)
+	case "config":
+		ifState.reset()
+		return evalConfigRule(env, rule)
+	case "init":
+		ifState.reset()
+		return evalInitRule(env, rule)
+	case "use", "import":
+		return ast.NilValue{}, fmt.Errorf("%s rules can only be used at the top level", rule.Name)
+	case "match":
+		ifState.reset()
+		return evalMatchRule(env, rule)
+	case "case", "default":
+		return ast.NilValue{}, fmt.Errorf("%s rules can only be used inside @match", rule.Name)
+	case "throw":
+		return evalThrowRule(env, rule)
+	case "catch", "finally":
+		return ast.NilValue{}, fmt.Errorf("%s rule must be preceded by a try rule", rule.Name)
+	case "break":
+		return BreakValue{Span: rule.Span}, nil
+	case "continue":
+		return ContinueValue{Span: rule.Span}, nil
 	}
 
 	return ast.NilValue{}, fmt.Errorf("at rules are not supported yet")
@@ -0,0 +1,131 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shreyassanthu77/cisp/ast"
+)
+
+// ConstraintError is returned when an argument does not satisfy the type
+// annotation or attribute operator of a parameter. Overload resolution uses
+// it to move on to the next clause.
+type ConstraintError struct {
+	Message string
+}
+
+func (e *ConstraintError) Error() string {
+	return e.Message
+}
+
+func constraintError(format string, args ...interface{}) error {
+	return &ConstraintError{Message: fmt.Sprintf(format, args...)}
+}
+
+func isConstraintError(err error) bool {
+	var cErr *ConstraintError
+	return errors.As(err, &cErr)
+}
+
+func isKnownType(name string) bool {
+	switch name {
+	case "any", "number", "int", "float", "string", "bool", "nil", "list", "map", "record", "enum", "function":
+		return true
+	}
+	return false
+}
+
+func verifyAttributeDefinition(attr ast.Attreibute) error {
+	if attr.Type.Name != "" && !isKnownType(attr.Type.Name) {
+		return fmt.Errorf("unknown type %s for parameter %s", attr.Type.Name, attr.Name.Name)
+	}
+	return nil
+}
+
+// checkType verifies that value matches the type annotation typ, promoting
+// ints to floats the same way binary operations do.
+func checkType(name string, typ string, value ast.Value) (ast.Value, error) {
+	valType := getValueType(value)
+	switch typ {
+	case "", "any":
+		return value, nil
+	case "number":
+		if valType == val_type_int || valType == val_type_float {
+			return value, nil
+		}
+	case "float":
+		if valType == val_type_int {
+			return ast.Float{Value: float64(value.(ast.Int).Value), Span: value.GetSpan()}, nil
+		}
+		if valType == val_type_float {
+			return value, nil
+		}
+	default:
+		if valType.String() == typ {
+			return value, nil
+		}
+	}
+
+	return nil, constraintError("parameter %s expects a %s but got %s", name, typ, valType)
+}
+
+func matchAttributeOperator(op string, value, operand string) bool {
+	switch op {
+	case "^=":
+		return strings.HasPrefix(value, operand)
+	case "$=":
+		return strings.HasSuffix(value, operand)
+	case "*=":
+		return strings.Contains(value, operand)
+	case "|=":
+		return value == operand || strings.HasPrefix(value, operand+"-")
+	case "~=":
+		for _, word := range strings.Fields(value) {
+			if word == operand {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func checkOperator(attr ast.Attreibute, value ast.Value, env *Environment) error {
+	operandVal, err := evalValue(attr.Operand, env)
+	if err != nil {
+		return err
+	}
+
+	operand, ok := operandVal.(ast.String)
+	if !ok {
+		return fmt.Errorf("the operand of %s for parameter %s must be a string", attr.Operator, attr.Name.Name)
+	}
+
+	str, ok := value.(ast.String)
+	if !ok {
+		return constraintError("parameter %s must be a string to match [%s%s\"%s\"] but got %s", attr.Name.Name, attr.Name.Name, attr.Operator, operand.Value, getValueType(value))
+	}
+
+	if !matchAttributeOperator(attr.Operator, str.Value, operand.Value) {
+		return constraintError("\"%s\" does not match [%s%s\"%s\"]", str.Value, attr.Name.Name, attr.Operator, operand.Value)
+	}
+	return nil
+}
+
+// checkAttribute validates an argument against the constraints of its
+// parameter and returns the value that should be bound to it.
+func checkAttribute(attr ast.Attreibute, value ast.Value, env *Environment) (ast.Value, error) {
+	value, err := checkType(attr.Name.Name, attr.Type.Name, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if attr.Operator != "" {
+		err = checkOperator(attr, value, env)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return value, nil
+}
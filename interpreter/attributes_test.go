@@ -0,0 +1,29 @@
+package interpreter
+
+import "testing"
+
+func TestAttributes(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"prefix", `f[s^="http"] { @return 1; } f[s] { @return 0; } main { @return (f("https://a"), f("ftp://a")); }`, "[1, 0]"},
+		{"suffix", `f[s$=".css"] { @return 1; } f[s] { @return 0; } main { @return (f("a.css"), f("a.js")); }`, "[1, 0]"},
+		{"substring", `f[s*="b"] { @return 1; } f[s] { @return 0; } main { @return (f("abc"), f("ac")); }`, "[1, 0]"},
+		{"dash", `f[s|="en"] { @return 1; } f[s] { @return 0; } main { @return (f("en"), f("en-US"), f("english")); }`, "[1, 1, 0]"},
+		{"includes", `f[s~="x"] { @return 1; } f[s] { @return 0; } main { @return (f("a x b"), f("axb")); }`, "[1, 0]"},
+		{"operators need strings", `f[s^="a"] { @return 1; } f[s] { @return 0; } main { @return f(1); }`, "0"},
+		{"typed", `f[n:int][s:string][b:bool][l:list] { @return 1; } main { @return f(1, "a", true, (1, 2)); }`, "1"},
+		{"ints are numbers", `f[n:number] { @return $n; } main { @return (f(1), f(1.5)); }`, "[1, 1.5]"},
+		{"ints become floats", `f[n:float] { @return type-of($n); } main { @return f(1); }`, `"float"`},
+		{"typed defaults", `f[n:number=3] { @return $n; } main { @return f(()); }`, "3"},
+		{"defaults", `f[n:int][m=2] { @return $n + $m; } main { @return (f(1, ()), f(1, 5)); }`, "[3, 6]"},
+	})
+}
+
+func TestAttributeErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"wrong type", "f[n:int] { @return $n; }\nmain {\n  @return f(1.5);\n}", "parameter n expects a int but got float", 3},
+		{"operator mismatch", "f[s$=\".css\"] { @return 1; }\nmain {\n  @return f(\"a.js\");\n}", `"a.js" does not match [s$=".css"]`, 3},
+		{"operator on a non string", "f[s$=\".css\"] { @return 1; }\nmain {\n  @return f(1);\n}", "parameter s must be a string", 3},
+		{"unknown type", "f[n:wat] { @return $n; }\nmain { }", "unknown type wat for parameter n", 1},
+		{"nil for a typed parameter", "f[n:int] { @return $n; }\nmain {\n  @return f(());\n}", "parameter n expects a int but got nil", 3},
+	})
+}
@@ -0,0 +1,22 @@
+package interpreter
+
+import "testing"
+
+func TestSpecificity(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"overload by arity", `f[x] { @return 1; } f[x][y] { @return 2; } main { @return (f(1), f(1, 2)); }`, "[1, 2]"},
+		{"typed beats untyped", `f[x] { @return "any"; } f[x:int] { @return "int"; } main { @return (f(1), f("a")); }`, `["int", "any"]`},
+		{"more typed parameters win", `f[x:int][y] { @return 1; } f[x:int][y:int] { @return 2; } main { @return (f(1, 2), f(1, "a")); }`, "[2, 1]"},
+		{"guards beat types", `f[x:int] { @return "int"; } f[x]:where($x > 0) { @return "where"; } main { @return (f(1), f(-1)); }`, `["where", "int"]`},
+		{"operators count like types", `f[s] { @return "other"; } f[s^="http"] { @return "url"; } main { @return (f("https://a"), f("ftp")); }`, `["url", "other"]`},
+		{"ties go to the earlier rule", `f[x] { @return "first"; } f[x] { @return "second"; } main { @return f(1); }`, `"first"`},
+		{"namespaced rules are more specific", `a b[x] { @return "deep"; } b[x] { @return "flat"; } main { @return (a.b(1), b(1)); }`, `["deep", "flat"]`},
+	})
+}
+
+func TestDispatchErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"no overload takes the arity", "f[x] { }\nf[x][y] { }\nmain {\n  @return f();\n}", "no overload of f takes 0 parameters", 4},
+		{"no clause matches", "f[x:int] { }\nf[x:string] { }\nmain {\n  @return f(1.5);\n}", "no clause of f matches the given arguments: parameter x expects a int but got float", 4},
+	})
+}
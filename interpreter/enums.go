@@ -0,0 +1,63 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/lexer"
+)
+
+// EnumValue is a variant of an enum declared with
+// `@enum direction[north][south];`, it is read as `$direction.north`.
+type EnumValue struct {
+	Enum    ast.Selector
+	Variant string
+	Span    lexer.Span
+}
+
+func (e EnumValue) IsValue() {}
+
+func (e EnumValue) GetSpan() lexer.Span {
+	return e.Span
+}
+
+func (e EnumValue) Name() string {
+	return e.Enum.Identifier.Name
+}
+
+func (e EnumValue) String() string {
+	return e.Name() + "." + e.Variant
+}
+
+// evalEnumRule declares every variant of an enum as a constant named after
+// the enum, the same way `$math.pi` is.
+func evalEnumRule(env *Environment, rule ast.AtRule) (ast.Value, error) {
+	if rule.Selector == nil {
+		return ast.NilValue{}, fmt.Errorf("enum rules should look like @enum direction[north][south]")
+	}
+
+	selector := *rule.Selector
+	if len(selector.Path) > 0 || len(selector.PseudoClasses) > 0 {
+		return ast.NilValue{}, fmt.Errorf("an enum can only be declared with a name and variants")
+	}
+	if len(selector.Atrributes) == 0 {
+		return ast.NilValue{}, fmt.Errorf("enum %s has no variants", selector.Identifier.Name)
+	}
+
+	for _, attr := range selector.Atrributes {
+		if !isNilValue(attr.Default) || attr.IsConstrained() {
+			return ast.NilValue{}, runtimeError(attr.Span, "enum variants can't have a type or a default value")
+		}
+
+		name := selector.Identifier.Name + "." + attr.Name.Name
+		if _, ok := env.Vars[name]; ok {
+			return ast.NilValue{}, runtimeError(attr.Span, "variant %s is declared twice", attr.Name.Name)
+		}
+
+		err := env.setImportantVar(name, EnumValue{Enum: selector, Variant: attr.Name.Name, Span: attr.Span})
+		if err != nil {
+			return ast.NilValue{}, runtimeError(attr.Span, "%s", err)
+		}
+	}
+	return ast.NilValue{}, nil
+}
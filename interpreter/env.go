@@ -2,58 +2,276 @@ package interpreter
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/lexer"
 )
 
+// Closure is a rule together with the environment it was defined in, which
+// becomes the parent scope of every call to it.
+type Closure struct {
+	Rule ast.Rule
+	Env  *Environment
+}
+
 type Environment struct {
 	Parent *Environment
-	Funcs  map[string]ast.Rule
-	Vars   map[string]ast.Value
+	// Funcs maps a function name to its clauses, in the order they should be
+	// tried when the function is called.
+	Funcs map[string][]Closure
+	// Namespaced holds the rules defined with descendant or child
+	// combinators, keyed by the name of the function they define.
+	Namespaced map[string][]Closure
+	Vars       map[string]ast.Value
+	// Important marks the variables of this scope declared with !important
+	Important map[string]bool
+	// Modules holds the modules brought in with `@use`, keyed by namespace
+	Modules map[string]*Environment
+	// Block marks the scope of a control flow body, like one iteration of a
+	// loop. Declaring a variable the enclosing rule already has assigns to
+	// it instead of shadowing it, like sass.
+	Block bool
+	// CallSite is the span of the call a rule's scope was created for.
+	// Natives that call back into the program, like map, report it as the
+	// location of those calls.
+	CallSite lexer.Span
 }
 
+var nativeFns = []ast.Rule{
+	printFn,
+	nthFn,
+	lengthFn,
+	appendFn,
+	concatFn,
+	mapGetFn,
+	mapMergeFn,
+	mapKeysFn,
+	mapValuesFn,
+	mapHasKeyFn,
+	mapFn,
+	filterFn,
+	reduceFn,
+	sortByFn,
+}
+
+// NewRootEnv creates the global scope of a module. The native functions
+// live in its parent so that a module only exports the rules it defines.
 func NewRootEnv() *Environment {
-	return &Environment{
-		Parent: nil,
-		Funcs: map[string]ast.Rule{
-			"print": printFn,
-		},
+	builtins := &Environment{
+		Parent:     nil,
+		Funcs:      make(map[string][]Closure),
+		Namespaced: make(map[string][]Closure),
+		Vars:       make(map[string]ast.Value),
+		Important:  make(map[string]bool),
+	}
+
+	for _, fn := range nativeFns {
+		builtins.setFn(fn)
+	}
+	for _, fn := range typeFns {
+		builtins.setFn(fn)
+	}
+	for _, fn := range stringFns {
+		builtins.setFn(fn)
 	}
+	for _, fn := range mathFns {
+		builtins.setFn(fn)
+	}
+	for name, val := range mathConstants {
+		builtins.setImportantVar(name, val)
+	}
+
+	env := builtins.fork()
+	env.Modules = make(map[string]*Environment)
+	return env
+}
+
+// isGlobalEnv reports whether env is the top level scope of a module.
+func isGlobalEnv(env *Environment) bool {
+	return env.Parent != nil && env.Parent.Parent == nil
 }
 
 func (e *Environment) fork() *Environment {
 	return &Environment{
-		Parent: e,
-		Funcs:  make(map[string]ast.Rule),
-		Vars:   make(map[string]ast.Value),
+		Parent:     e,
+		Funcs:      make(map[string][]Closure),
+		Namespaced: make(map[string][]Closure),
+		Vars:       make(map[string]ast.Value),
+		Important:  make(map[string]bool),
+	}
+}
+
+// blockFork creates the scope of a control flow body run in e.
+func (e *Environment) blockFork() *Environment {
+	env := e.fork()
+	env.Block = true
+	return env
+}
+
+// matchPath reports whether the selector path matches the namespaces a
+// function was called through, working right to left the way css matches
+// ancestors.
+func matchPath(path []ast.SelectorSegment, namespaces []string) bool {
+	if len(path) == 0 {
+		return true
 	}
+
+	last := path[len(path)-1]
+	rest := path[:len(path)-1]
+	if last.Combinator == ast.COMBINATOR_CHILD {
+		n := len(namespaces)
+		if n == 0 || namespaces[n-1] != last.Name.Name {
+			return false
+		}
+		return matchPath(rest, namespaces[:n-1])
+	}
+
+	for i := len(namespaces) - 1; i >= 0; i-- {
+		if namespaces[i] == last.Name.Name && matchPath(rest, namespaces[:i]) {
+			return true
+		}
+	}
+	return false
 }
 
-func (e *Environment) genFn(name string) (ast.Rule, error) {
+// genNamespacedFn resolves a qualified name such as `math.trig.sin` against
+// the rules in this scope that were declared with a selector path.
+func (e *Environment) genNamespacedFn(name string) ([]Closure, bool) {
+	parts := strings.Split(name, ".")
+	if len(parts) < 2 {
+		return nil, false
+	}
+
+	namespaces, fnName := parts[:len(parts)-1], parts[len(parts)-1]
+	var clauses []Closure
+	for _, fn := range e.Namespaced[fnName] {
+		if matchPath(fn.Rule.Selector.Path, namespaces) {
+			clauses = append(clauses, fn)
+		}
+	}
+	return clauses, len(clauses) > 0
+}
+
+// genFn returns the clauses of the function called name.
+func (e *Environment) genFn(name string) ([]Closure, error) {
 	fn, ok := e.Funcs[name]
+	if !ok {
+		fn, ok = e.genNamespacedFn(name)
+	}
+
+	if !ok {
+		fn, ok = e.genModuleFn(name)
+	}
+
 	if !ok && e.Parent != nil {
 		return e.Parent.genFn(name)
 	}
 
 	if !ok {
-		return ast.Rule{}, fmt.Errorf("function %s not found", name)
+		return nil, fmt.Errorf("function %s not found", name)
 	}
 
 	return fn, nil
 }
 
+// addClause adds fn to the dispatch table of a function. Clauses are kept
+// ordered from the most to the least specific selector and, for equally
+// specific selectors, in source order so that the earlier definition wins.
+func addClause(clauses []Closure, fn Closure) []Closure {
+	specificity := fn.Rule.Selector.Specificity()
+	i := 0
+	for i < len(clauses) && clauses[i].Rule.Selector.Specificity().Compare(specificity) >= 0 {
+		i++
+	}
+	return append(clauses[:i:i], append([]Closure{fn}, clauses[i:]...)...)
+}
+
+// verifySelector checks the parts of a rule's selector that can be wrong
+// without being a syntax error.
+func verifySelector(selector ast.Selector) error {
+	for _, pseudo := range selector.PseudoClasses {
+		if !isKnownPseudoClass(pseudo.Name.Name) {
+			return fmt.Errorf("unknown pseudo-class :%s", pseudo.Name.Name)
+		}
+	}
+
+	for _, attr := range selector.Atrributes {
+		err := verifyAttributeDefinition(attr)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (e *Environment) setFn(fn ast.Rule) error {
+	err := verifySelector(fn.Selector)
+	if err != nil {
+		return err
+	}
+
+	closure := Closure{Rule: fn, Env: e}
 	name := fn.Selector.Identifier.Name
-	_, ok := e.Funcs[name]
-	if ok {
-		return fmt.Errorf("function %s already defined in this scope", name)
+	if len(fn.Selector.Path) > 0 {
+		e.Namespaced[name] = addClause(e.Namespaced[name], closure)
+		return nil
+	}
+
+	e.Funcs[name] = addClause(e.Funcs[name], closure)
+	return nil
+}
+
+// isImportant reports whether the closest declaration of name is a constant.
+func (e *Environment) isImportant(name string) bool {
+	if _, ok := e.Vars[name]; ok {
+		return e.Important[name]
 	}
 
-	e.Funcs[name] = fn
+	if e.Parent != nil {
+		return e.Parent.isImportant(name)
+	}
+
+	return false
+}
+
+func (e *Environment) setVar(name string, value ast.Value) error {
+	if e.isImportant(name) {
+		return fmt.Errorf("cannot reassign --%s, it was declared !important", name)
+	}
+
+	e.assignTarget(name).Vars[name] = value
+	return nil
+}
+
+// assignTarget returns the scope a declaration of name in e writes to: the
+// closest scope up to the enclosing rule that declares it already, or e.
+func (e *Environment) assignTarget(name string) *Environment {
+	for env := e; env != nil; env = env.Parent {
+		if _, ok := env.Vars[name]; ok {
+			return env
+		}
+		if !env.Block {
+			break
+		}
+	}
+	return e
+}
+
+// setImportantVar declares a constant, always in e itself.
+func (e *Environment) setImportantVar(name string, value ast.Value) error {
+	if e.isImportant(name) {
+		return fmt.Errorf("cannot reassign --%s, it was declared !important", name)
+	}
+
+	e.Vars[name] = value
+	e.Important[name] = true
 	return nil
 }
 
-func (e *Environment) setVar(name string, value ast.Value) {
+// bindParam binds a parameter of a call, parameters are allowed to shadow
+// important variables.
+func (e *Environment) bindParam(name string, value ast.Value) {
 	e.Vars[name] = value
 }
 
@@ -0,0 +1,102 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/diagnostic"
+	"github.com/shreyassanthu77/cisp/lexer"
+)
+
+// maxRenderedFrames caps how much of a deep call stack ends up in a
+// diagnostic, the innermost and outermost frames are kept.
+const maxRenderedFrames = 12
+
+// Frame is one rule invocation on the CRAP call stack.
+type Frame struct {
+	Name     string
+	CallSite lexer.Span
+}
+
+func (f Frame) String() string {
+	if f.CallSite.Start.Line == 0 {
+		return fmt.Sprintf("in %s", f.Name)
+	}
+	return fmt.Sprintf("in %s, called at %d:%d", f.Name, f.CallSite.Start.Line, f.CallSite.Start.Col)
+}
+
+// RuntimeError is an error raised while evaluating a program. Span points at
+// the node that failed and Stack lists the rules that were being evaluated,
+// innermost first.
+type RuntimeError struct {
+	Message string
+	Span    lexer.Span
+	Stack   []Frame
+	// Value is what was thrown with `@throw`, nil for errors raised by the
+	// interpreter itself.
+	Value ast.Value
+}
+
+func (e *RuntimeError) Error() string {
+	if e.Span.Start.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("{%d:%d} %s", e.Span.Start.Line, e.Span.Start.Col, e.Message)
+}
+
+func (e *RuntimeError) Diagnostic() diagnostic.Diagnostic {
+	diag := diagnostic.Errorf(e.Span, "%s", e.Message)
+
+	frames := e.Stack
+	skipped := 0
+	if len(frames) > maxRenderedFrames {
+		skipped = len(frames) - maxRenderedFrames
+		frames = append(frames[:maxRenderedFrames/2:maxRenderedFrames/2], frames[len(frames)-maxRenderedFrames/2:]...)
+	}
+
+	for i, frame := range frames {
+		if skipped > 0 && i == maxRenderedFrames/2 {
+			diag = diag.WithNote("... %d more frames", skipped)
+		}
+		diag = diag.WithNote("%s", frame)
+	}
+
+	return diag
+}
+
+func runtimeError(span lexer.Span, format string, args ...interface{}) error {
+	return &RuntimeError{
+		Message: fmt.Sprintf(format, args...),
+		Span:    span,
+	}
+}
+
+func asRuntimeError(err error) *RuntimeError {
+	var rtErr *RuntimeError
+	if errors.As(err, &rtErr) {
+		return rtErr
+	}
+	return &RuntimeError{Message: err.Error()}
+}
+
+// withSpan attaches span to err unless a more precise location has already
+// been recorded closer to where the error happened.
+func withSpan(err error, span lexer.Span) error {
+	rtErr := asRuntimeError(err)
+	if rtErr.Span.Start.Line == 0 {
+		rtErr.Span = span
+	}
+	return rtErr
+}
+
+// pushFrame records that err propagated out of the rule name called at
+// callSite.
+func pushFrame(err error, name string, callSite lexer.Span) error {
+	rtErr := asRuntimeError(withSpan(err, callSite))
+	rtErr.Stack = append(rtErr.Stack, Frame{
+		Name:     name,
+		CallSite: callSite,
+	})
+	return rtErr
+}
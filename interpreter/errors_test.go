@@ -0,0 +1,60 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestErrorSpans(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"division by zero", "main {\n  @return 1 / 0;\n}", "division by zero", 2},
+		{"unknown variable", "main {\n  --x: 1;\n  @return $y;\n}", "variable y not found", 3},
+		{"unknown function", "main {\n\n  @return nope(1);\n}", "function nope not found", 3},
+		{"inside a call", "f[x] {\n  @return $x + \"a\" * 2;\n}\nmain {\n  @return f(1);\n}", "invalid types for binary operation", 2},
+		{"wrong number of arguments", "f[x] { }\nmain {\n  @return f(1, 2);\n}", "expected 1 parameters, got 2", 3},
+		{"no main", "f { }", "no main rule found", 0},
+	})
+}
+
+func TestStack(t *testing.T) {
+	src := "inner {\n  @return 1 / 0;\n}\nouter {\n  @return inner();\n}\nmain {\n  @return outer();\n}"
+	_, err := run(t, src)
+	rtErr, ok := err.(*RuntimeError)
+	if !ok {
+		t.Fatalf("got %v, want a runtime error", err)
+	}
+
+	want := []string{"in inner, called at 5:11", "in outer, called at 8:11", "in main"}
+	got := []string{}
+	for _, frame := range rtErr.Stack {
+		got = append(got, frame.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got the stack %q, want %q", got, want)
+	}
+
+	diag := rtErr.Diagnostic()
+	if diag.Span.Start.Line != 2 || len(diag.Notes) != len(want) {
+		t.Errorf("got a diagnostic at line %d with notes %q", diag.Span.Start.Line, diag.Notes)
+	}
+}
+
+func TestDeepStacksAreTruncated(t *testing.T) {
+	_, err := run(t, "down[n] {\n  @if $n == 0 { @return 1 / 0; }\n  @return down($n - 1);\n}\nmain { @return down(50); }")
+	rtErr, ok := err.(*RuntimeError)
+	if !ok {
+		t.Fatalf("got %v, want a runtime error", err)
+	}
+	if len(rtErr.Stack) != 52 {
+		t.Errorf("got %d frames, want 52", len(rtErr.Stack))
+	}
+
+	notes := rtErr.Diagnostic().Notes
+	if len(notes) != maxRenderedFrames+1 {
+		t.Fatalf("got %d notes, want %d", len(notes), maxRenderedFrames+1)
+	}
+	if want := fmt.Sprintf("... %d more frames", 52-maxRenderedFrames); notes[maxRenderedFrames/2] != want {
+		t.Errorf("got %q, want %q", notes[maxRenderedFrames/2], want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/lexer"
 )
 
 func isNilValue(v ast.Value) bool {
@@ -18,16 +19,39 @@ func evalVarDeclaration(decl ast.Declaration, env *Environment) (ast.Value, erro
 		return ast.NilValue{}, fmt.Errorf("variable declaration should have exactly one value")
 	}
 	value, err := evalValue(decl.Parameters[0], env)
+	if err != nil {
+		return ast.NilValue{}, err
+	}
 
 	val, err := evalValue(value, env)
 	if err != nil {
 		return ast.NilValue{}, err
 	}
-	env.setVar(name, val)
+
+	if decl.Important {
+		err = env.setImportantVar(name, val)
+	} else {
+		err = env.setVar(name, val)
+	}
+	if err != nil {
+		return ast.NilValue{}, err
+	}
 	return val, nil
 }
 
+func isVariable(name string) bool {
+	return len(name) > 2 && name[:2] == "--"
+}
+
 func evalStmt(stmt ast.Statement, ifState *IfState, env *Environment) (ast.Value, error) {
+	res, err := evalStmtNode(stmt, ifState, env)
+	if err != nil {
+		return ast.NilValue{}, withSpan(err, stmt.GetSpan())
+	}
+	return res, nil
+}
+
+func evalStmtNode(stmt ast.Statement, ifState *IfState, env *Environment) (ast.Value, error) {
 	switch stmt := stmt.(type) {
 	case ast.Rule:
 		err := env.setFn(stmt)
@@ -39,18 +63,23 @@ func evalStmt(stmt ast.Statement, ifState *IfState, env *Environment) (ast.Value
 	case ast.AtRule:
 		return evalAtRule(env, ifState, stmt)
 	case ast.Declaration:
-		if len(stmt.Property.Name) > 2 && stmt.Property.Name[:2] == "--" {
+		if isVariable(stmt.Property.Name) {
 			return evalVarDeclaration(stmt, env)
 		}
 		fnCall := ast.FunctionCall{
 			Fn:         stmt.Property,
 			Parameters: stmt.Parameters,
+			Span:       stmt.Span,
 		}
 		ifState.reset()
 		return evalFnCall(fnCall, env)
 	case NativeFnCall:
 		ifState.reset()
-		return stmt.Handler(env)
+		res, err := stmt.Handler(env)
+		if err != nil {
+			return ast.NilValue{}, err
+		}
+		return ReturnValue{Value: res}, nil
 	}
 
 	return ast.NilValue{}, nil
@@ -70,7 +99,12 @@ func verifyAndAddParamsToEnv(attributes []ast.Attreibute, params []ast.Value, en
 				return fmt.Errorf("parameter %s is required", attr.Name.Name)
 			}
 		}
-		env.setVar(attr.Name.Name, param)
+
+		param, err := checkAttribute(attr, param, env)
+		if err != nil {
+			return err
+		}
+		env.bindParam(attr.Name.Name, param)
 	}
 
 	return nil
@@ -80,25 +114,47 @@ func evalStatementList(stmts []ast.Statement, env *Environment) (ast.Value, erro
 	var res ast.Value = ast.NilValue{}
 	var err error
 	ifState := IfState{}
-	for _, stmt := range stmts {
-		res, err = evalStmt(stmt, &ifState, env)
+	for i := 0; i < len(stmts); i++ {
+		if isTryRule(stmts[i]) {
+			ifState.reset()
+			var n int
+			res, n, err = evalTryStatement(env, stmts[i:])
+			i += n - 1
+		} else {
+			res, err = evalStmt(stmts[i], &ifState, env)
+		}
 		if err != nil {
 			return ast.NilValue{}, err
 		}
-		if isReturnValue(res) {
+		if isControlFlow(res) {
 			break
 		}
 	}
 	return res, err
 }
 
-func evalRule(rule ast.Rule, params []ast.Value, parent *Environment) (ast.Value, error) {
+// bindRule creates the environment a call to rule runs in and reports
+// whether the guards of the rule accept the arguments.
+func bindRule(rule ast.Rule, params []ast.Value, parent *Environment) (*Environment, bool, error) {
+	if isVariadic(rule) && takesArity(rule, len(params)) {
+		params = packArgs(rule, params)
+	}
+
 	env := parent.fork()
 	err := verifyAndAddParamsToEnv(rule.Selector.Atrributes, params, env)
 	if err != nil {
-		return ast.NilValue{}, err
+		return nil, false, err
+	}
+
+	ok, err := guardsHold(rule.Selector, env)
+	if err != nil || !ok {
+		return nil, false, err
 	}
 
+	return env, true, nil
+}
+
+func evalRule(rule ast.Rule, env *Environment) (ast.Value, error) {
 	res, err := evalStatementList(rule.Body, env)
 	if err != nil {
 		return ReturnValue{
@@ -106,9 +162,70 @@ func evalRule(rule ast.Rule, params []ast.Value, parent *Environment) (ast.Value
 		}, err
 	}
 
-	if isReturnValue(res) {
-		return res.(ReturnValue).Value, nil
+	switch res := res.(type) {
+	case ReturnValue:
+		return res.Value, nil
+	case BreakValue:
+		return ast.NilValue{}, runtimeError(res.Span, "@break used outside of a loop")
+	case ContinueValue:
+		return ast.NilValue{}, runtimeError(res.Span, "@continue used outside of a loop")
 	}
 
 	return ast.NilValue{}, nil
 }
+
+// callFn dispatches a call to the most specific clause of a function that
+// takes as many parameters as were given and whose guards hold.
+func callFn(clauses []Closure, params []ast.Value, callSite lexer.Span) (ast.Value, error) {
+	if len(clauses) == 1 {
+		return callClosure(clauses[0], params, callSite)
+	}
+
+	arityMatched := false
+	var violation error
+	for _, fn := range clauses {
+		rule := fn.Rule
+		if !takesArity(rule, len(params)) {
+			continue
+		}
+		arityMatched = true
+
+		env, ok, err := bindRule(rule, params, fn.Env)
+		if isConstraintError(err) {
+			if violation == nil {
+				violation = err
+			}
+			continue
+		}
+		if err != nil {
+			return ast.NilValue{}, err
+		}
+
+		if ok {
+			env.CallSite = callSite
+			return evalRule(rule, env)
+		}
+	}
+
+	name := clauses[0].Rule.Selector.Identifier.Name
+	if !arityMatched {
+		return ast.NilValue{}, fmt.Errorf("no overload of %s takes %d parameters", name, len(params))
+	}
+	if violation != nil {
+		return ast.NilValue{}, fmt.Errorf("no clause of %s matches the given arguments: %s", name, violation)
+	}
+	return ast.NilValue{}, fmt.Errorf("no clause of %s matches the given arguments", name)
+}
+
+func callClosure(fn Closure, params []ast.Value, callSite lexer.Span) (ast.Value, error) {
+	env, ok, err := bindRule(fn.Rule, params, fn.Env)
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	if !ok {
+		return ast.NilValue{}, fmt.Errorf("no clause of %s matches the given arguments", fn.Rule.Selector.Identifier.Name)
+	}
+	env.CallSite = callSite
+	return evalRule(fn.Rule, env)
+}
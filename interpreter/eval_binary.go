@@ -1,7 +1,10 @@
 package interpreter
 
 import (
+	"cmp"
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/shreyassanthu77/cisp/ast"
 )
@@ -15,8 +18,39 @@ const (
 	val_type_string
 	val_type_boolean
 	val_type_nil
+	val_type_list
+	val_type_map
+	val_type_record
+	val_type_enum
+	val_type_function
 )
 
+func (t ValueType) String() string {
+	switch t {
+	case val_type_int:
+		return "int"
+	case val_type_float:
+		return "float"
+	case val_type_string:
+		return "string"
+	case val_type_boolean:
+		return "bool"
+	case val_type_nil:
+		return "nil"
+	case val_type_list:
+		return "list"
+	case val_type_map:
+		return "map"
+	case val_type_record:
+		return "record"
+	case val_type_enum:
+		return "enum"
+	case val_type_function:
+		return "function"
+	}
+	return "unknown"
+}
+
 func getValueType(v ast.Value) ValueType {
 	switch v.(type) {
 	case ast.Int:
@@ -29,6 +63,16 @@ func getValueType(v ast.Value) ValueType {
 		return val_type_boolean
 	case ast.NilValue:
 		return val_type_nil
+	case ast.List:
+		return val_type_list
+	case ast.Map:
+		return val_type_map
+	case RecordValue:
+		return val_type_record
+	case EnumValue:
+		return val_type_enum
+	case FunctionValue:
+		return val_type_function
 	default:
 		return val_type_unknown
 	}
@@ -72,6 +116,9 @@ func evalMul(left, right ast.Value, leftType ValueType) (ast.Value, error) {
 func evalDiv(left, right ast.Value, leftType ValueType) (ast.Value, error) {
 	switch leftType {
 	case val_type_int:
+		if right.(ast.Int).Value == 0 {
+			return ast.NilValue{}, fmt.Errorf("division by zero")
+		}
 		return ast.Int{Value: left.(ast.Int).Value / right.(ast.Int).Value}, nil
 	case val_type_float:
 		return ast.Float{Value: left.(ast.Float).Value / right.(ast.Float).Value}, nil
@@ -80,6 +127,73 @@ func evalDiv(left, right ast.Value, leftType ValueType) (ast.Value, error) {
 	}
 }
 
+// evalMod is floored like sass, the result has the sign of the divisor.
+func evalMod(left, right ast.Value, leftType ValueType) (ast.Value, error) {
+	switch leftType {
+	case val_type_int:
+		l, r := left.(ast.Int).Value, right.(ast.Int).Value
+		if r == 0 {
+			return ast.NilValue{}, fmt.Errorf("modulo by zero")
+		}
+		m := l % r
+		if m != 0 && (m < 0) != (r < 0) {
+			m += r
+		}
+		return ast.Int{Value: m}, nil
+	case val_type_float:
+		l, r := left.(ast.Float).Value, right.(ast.Float).Value
+		m := math.Mod(l, r)
+		if m != 0 && (m < 0) != (r < 0) {
+			m += r
+		}
+		return ast.Float{Value: m}, nil
+	default:
+		return ast.NilValue{}, fmt.Errorf("invalid types for modulo: %T and %T", left, right)
+	}
+}
+
+// evalPow works like math.pow, two ints give an int unless the exponent is
+// negative or the result is too large for an int.
+func evalPow(left, right ast.Value, leftType ValueType) (ast.Value, error) {
+	switch leftType {
+	case val_type_int:
+		base, exp := left.(ast.Int).Value, right.(ast.Int).Value
+		if exp >= 0 {
+			if n, ok := intPow(base, exp); ok {
+				return ast.Int{Value: n}, nil
+			}
+		}
+		return ast.Float{Value: math.Pow(float64(base), float64(exp))}, nil
+	case val_type_float:
+		return ast.Float{Value: math.Pow(left.(ast.Float).Value, right.(ast.Float).Value)}, nil
+	default:
+		return ast.NilValue{}, fmt.Errorf("invalid types for exponentiation: %T and %T", left, right)
+	}
+}
+
+// evalBitwise applies `&`, `|`, `<<` and `>>`, they only work on ints.
+func evalBitwise(op string, left, right ast.Value, leftType ValueType) (ast.Value, error) {
+	if leftType != val_type_int {
+		return ast.NilValue{}, fmt.Errorf("invalid types for %s: %T and %T", op, left, right)
+	}
+
+	l, r := left.(ast.Int).Value, right.(ast.Int).Value
+	switch op {
+	case "&":
+		return ast.Int{Value: l & r}, nil
+	case "|":
+		return ast.Int{Value: l | r}, nil
+	}
+
+	if r < 0 || r >= 64 {
+		return ast.NilValue{}, fmt.Errorf("cannot shift by %d, the amount must be between 0 and 63", r)
+	}
+	if op == "<<" {
+		return ast.Int{Value: l << r}, nil
+	}
+	return ast.Int{Value: l >> r}, nil
+}
+
 func evalEq(left, right ast.Value, leftType ValueType) (ast.Value, error) {
 	switch leftType {
 	case val_type_int:
@@ -92,17 +206,120 @@ func evalEq(left, right ast.Value, leftType ValueType) (ast.Value, error) {
 		return ast.Boolean{Value: left.(ast.Boolean).Value == right.(ast.Boolean).Value}, nil
 	case val_type_nil:
 		return ast.Boolean{Value: true}, nil
+	case val_type_list, val_type_map, val_type_record, val_type_enum, val_type_function:
+		return ast.Boolean{Value: valuesEqual(left, right)}, nil
 	default:
 		return ast.NilValue{}, fmt.Errorf("invalid types for equality: %T and %T", left, right)
 	}
 }
 
+// valuesEqual compares two values of any type, values of different types
+// are never equal except for ints and floats.
+func valuesEqual(left, right ast.Value) bool {
+	leftType := getValueType(left)
+	rightType := getValueType(right)
+	if leftType == val_type_int && rightType == val_type_float {
+		left = ast.Float{Value: float64(left.(ast.Int).Value)}
+		leftType = val_type_float
+	} else if leftType == val_type_float && rightType == val_type_int {
+		right = ast.Float{Value: float64(right.(ast.Int).Value)}
+	}
+
+	if leftType != getValueType(right) {
+		return false
+	}
+
+	if leftType == val_type_list {
+		l, r := left.(ast.List).Values, right.(ast.List).Values
+		if len(l) != len(r) {
+			return false
+		}
+		for i := range l {
+			if !valuesEqual(l[i], r[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if leftType == val_type_map {
+		l, r := left.(ast.Map).Entries, right.(ast.Map).Entries
+		if len(l) != len(r) {
+			return false
+		}
+		for _, entry := range l {
+			i, ok := mapIndex(r, entry.Key)
+			if !ok || !valuesEqual(entry.Value, r[i].Value) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if leftType == val_type_function {
+		return left.(FunctionValue).equals(right.(FunctionValue))
+	}
+
+	if leftType == val_type_record {
+		l, r := left.(RecordValue), right.(RecordValue)
+		if l.Type.Span != r.Type.Span || l.Name() != r.Name() {
+			return false
+		}
+		for i := range l.Values {
+			if !valuesEqual(l.Values[i], r.Values[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if leftType == val_type_enum {
+		l, r := left.(EnumValue), right.(EnumValue)
+		return l.Enum.Span == r.Enum.Span && l.Variant == r.Variant
+	}
+
+	eq, err := evalEq(left, right, leftType)
+	if err != nil {
+		return false
+	}
+	return eq.(ast.Boolean).Value
+}
+
+// compareValues orders two numbers or two strings, it returns a negative
+// number when left comes first, a positive one when right does and 0 when
+// they are equal.
+func compareValues(left, right ast.Value) (int, error) {
+	leftType := getValueType(left)
+	rightType := getValueType(right)
+
+	switch {
+	case leftType == val_type_string && rightType == val_type_string:
+		return strings.Compare(left.(ast.String).Value, right.(ast.String).Value), nil
+	case leftType == val_type_int && rightType == val_type_int:
+		return cmp.Compare(left.(ast.Int).Value, right.(ast.Int).Value), nil
+	case (leftType == val_type_int || leftType == val_type_float) && (rightType == val_type_int || rightType == val_type_float):
+		return cmp.Compare(toFloat(left), toFloat(right)), nil
+	}
+
+	return 0, fmt.Errorf("cannot compare %s and %s", leftType, rightType)
+}
+
+// toFloat returns the value of an int or a float as a float.
+func toFloat(v ast.Value) float64 {
+	if i, ok := v.(ast.Int); ok {
+		return float64(i.Value)
+	}
+	return v.(ast.Float).Value
+}
+
 func evalLt(left, right ast.Value, leftType ValueType) (ast.Value, error) {
 	switch leftType {
 	case val_type_int:
 		return ast.Boolean{Value: left.(ast.Int).Value < right.(ast.Int).Value}, nil
 	case val_type_float:
 		return ast.Boolean{Value: left.(ast.Float).Value < right.(ast.Float).Value}, nil
+	case val_type_string:
+		return ast.Boolean{Value: strings.Compare(left.(ast.String).Value, right.(ast.String).Value) < 0}, nil
 	default:
 		return ast.NilValue{}, fmt.Errorf("invalid types for less than: %T and %T", left, right)
 	}
@@ -114,6 +331,8 @@ func evalLe(left, right ast.Value, leftType ValueType) (ast.Value, error) {
 		return ast.Boolean{Value: left.(ast.Int).Value <= right.(ast.Int).Value}, nil
 	case val_type_float:
 		return ast.Boolean{Value: left.(ast.Float).Value <= right.(ast.Float).Value}, nil
+	case val_type_string:
+		return ast.Boolean{Value: strings.Compare(left.(ast.String).Value, right.(ast.String).Value) <= 0}, nil
 	default:
 		return ast.NilValue{}, fmt.Errorf("invalid types for less than or equal: %T and %T", left, right)
 	}
@@ -125,6 +344,8 @@ func evalGt(left, right ast.Value, leftType ValueType) (ast.Value, error) {
 		return ast.Boolean{Value: left.(ast.Int).Value > right.(ast.Int).Value}, nil
 	case val_type_float:
 		return ast.Boolean{Value: left.(ast.Float).Value > right.(ast.Float).Value}, nil
+	case val_type_string:
+		return ast.Boolean{Value: strings.Compare(left.(ast.String).Value, right.(ast.String).Value) > 0}, nil
 	default:
 		return ast.NilValue{}, fmt.Errorf("invalid types for greater than: %T and %T", left, right)
 	}
@@ -136,6 +357,8 @@ func evalGe(left, right ast.Value, leftType ValueType) (ast.Value, error) {
 		return ast.Boolean{Value: left.(ast.Int).Value >= right.(ast.Int).Value}, nil
 	case val_type_float:
 		return ast.Boolean{Value: left.(ast.Float).Value >= right.(ast.Float).Value}, nil
+	case val_type_string:
+		return ast.Boolean{Value: strings.Compare(left.(ast.String).Value, right.(ast.String).Value) >= 0}, nil
 	default:
 		return ast.NilValue{}, fmt.Errorf("invalid types for greater than or equal: %T and %T", left, right)
 	}
@@ -188,6 +411,12 @@ func evalBinaryOp(op ast.BinaryOp, env *Environment) (ast.Value, error) {
 		return evalMul(left, right, leftType)
 	case "/":
 		return evalDiv(left, right, leftType)
+	case "%":
+		return evalMod(left, right, leftType)
+	case "^":
+		return evalPow(left, right, leftType)
+	case "&", "|", "<<", ">>":
+		return evalBitwise(op.Op, left, right, leftType)
 	case "<":
 		return evalLt(left, right, leftType)
 	case "<=":
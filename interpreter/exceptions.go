@@ -0,0 +1,193 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/shreyassanthu77/cisp/ast"
+)
+
+// errorType is the record a `@catch [e]` block receives, it behaves like a
+// type declared with `@type error[message][value][span][stack];`
+var errorType = ast.Selector{
+	Identifier: ast.Identifier{Name: "error"},
+	Atrributes: []ast.Attreibute{
+		param("message", "string"),
+		param("value", ""),
+		param("span", ""),
+		param("stack", "list"),
+	},
+}
+
+// spanType is the record stored in the span field of a caught error.
+var spanType = ast.Selector{
+	Identifier: ast.Identifier{Name: "span"},
+	Atrributes: []ast.Attreibute{
+		param("line", "int"),
+		param("column", "int"),
+	},
+}
+
+// isErrorRecord reports whether v was made by a `@catch`. Records are of the
+// same type when their types are declared at the same place, so a record of
+// a user type that is also called error isn't one.
+func isErrorRecord(v ast.Value) bool {
+	record, ok := v.(RecordValue)
+	return ok && record.Type.Span == errorType.Span && record.Name() == errorType.Identifier.Name
+}
+
+// evalThrowRule raises the value of `@throw $value;` as a runtime error.
+// Throwing a caught error again keeps its message and value.
+func evalThrowRule(env *Environment, rule ast.AtRule) (ast.Value, error) {
+	if len(rule.Parameters) != 1 {
+		return ast.NilValue{}, fmt.Errorf("throw rules should have exactly one parameter")
+	}
+
+	value, err := evalValue(rule.Parameters[0], env)
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	if isErrorRecord(value) {
+		record := value.(RecordValue)
+		message, _ := record.field("message")
+		thrown, _ := record.field("value")
+		return ast.NilValue{}, &RuntimeError{
+			Message: stringify(message),
+			Span:    rule.Span,
+			Value:   thrown,
+		}
+	}
+
+	return ast.NilValue{}, &RuntimeError{
+		Message: stringify(value),
+		Span:    rule.Span,
+		Value:   value,
+	}
+}
+
+// caughtError turns err into the record bound by `@catch [e]`. Errors raised
+// by the interpreter carry their message as value.
+func caughtError(err error) RecordValue {
+	rtErr := asRuntimeError(err)
+
+	value := rtErr.Value
+	if value == nil {
+		value = ast.String{Value: rtErr.Message}
+	}
+
+	stack := make([]ast.Value, len(rtErr.Stack))
+	for i, frame := range rtErr.Stack {
+		stack[i] = ast.String{Value: frame.String()}
+	}
+
+	span := RecordValue{
+		Type: spanType,
+		Values: []ast.Value{
+			ast.Int{Value: int64(rtErr.Span.Start.Line)},
+			ast.Int{Value: int64(rtErr.Span.Start.Col)},
+		},
+		Span: rtErr.Span,
+	}
+
+	return RecordValue{
+		Type: errorType,
+		Values: []ast.Value{
+			ast.String{Value: rtErr.Message},
+			value,
+			span,
+			ast.List{Values: stack},
+		},
+		Span: rtErr.Span,
+	}
+}
+
+// tryHandlers collects the `@catch` and `@finally` rules that follow the
+// `@try` at stmts[0], it returns them with the number of statements used.
+func tryHandlers(stmts []ast.Statement) (catch *ast.AtRule, finally *ast.AtRule, n int, err error) {
+	n = 1
+	for _, stmt := range stmts[1:] {
+		rule, ok := stmt.(ast.AtRule)
+		if !ok || (rule.Name != "catch" && rule.Name != "finally") {
+			break
+		}
+
+		if finally != nil {
+			return nil, nil, 0, runtimeError(rule.Span, "@%s can't follow @finally", rule.Name)
+		}
+
+		if rule.Name == "catch" {
+			if catch != nil {
+				return nil, nil, 0, runtimeError(rule.Span, "a try rule can only have one @catch")
+			}
+			catch = &rule
+		} else {
+			finally = &rule
+		}
+		n++
+	}
+
+	if catch == nil && finally == nil {
+		return nil, nil, 0, fmt.Errorf("try rule must be followed by a catch or a finally rule")
+	}
+	return catch, finally, n, nil
+}
+
+// evalCatchRule runs a `@catch` in a scope of its own, the error it binds
+// doesn't outlive it.
+func evalCatchRule(env *Environment, rule ast.AtRule, thrown error) (ast.Value, error) {
+	catchEnv := env.blockFork()
+	if rule.Selector != nil {
+		attrs := rule.Selector.Atrributes
+		if len(attrs) != 1 || attrs[0].IsConstrained() {
+			return ast.NilValue{}, runtimeError(rule.Selector.Span, "catch rules bind the error to exactly one name, @catch [e]")
+		}
+
+		catchEnv.bindParam(attrs[0].Name.Name, caughtError(thrown))
+	}
+
+	return evalStatementList(rule.Body, catchEnv)
+}
+
+// evalTryRule runs a `@try` block and its handlers. `@finally` always runs,
+// and a `@return`, `@break` or `@continue` inside it wins over the outcome
+// of the other blocks.
+func evalTryRule(env *Environment, rule ast.AtRule, catch *ast.AtRule, finally *ast.AtRule) (ast.Value, error) {
+	if len(rule.Parameters) != 0 {
+		return ast.NilValue{}, fmt.Errorf("try rules don't take parameters")
+	}
+
+	res, err := evalStatementList(rule.Body, env.blockFork())
+	if err != nil && catch != nil {
+		res, err = evalCatchRule(env, *catch, err)
+	}
+
+	if finally != nil {
+		finallyRes, finallyErr := evalStatementList(finally.Body, env.blockFork())
+		if finallyErr != nil {
+			return ast.NilValue{}, finallyErr
+		}
+		if isControlFlow(finallyRes) {
+			return finallyRes, nil
+		}
+	}
+
+	return res, err
+}
+
+// evalTryStatement evaluates the `@try` at stmts[0] and reports how many
+// statements it consumed.
+func evalTryStatement(env *Environment, stmts []ast.Statement) (ast.Value, int, error) {
+	rule := stmts[0].(ast.AtRule)
+	catch, finally, n, err := tryHandlers(stmts)
+	if err != nil {
+		return ast.NilValue{}, 0, withSpan(err, rule.Span)
+	}
+
+	res, err := evalTryRule(env, rule, catch, finally)
+	return res, n, err
+}
+
+func isTryRule(stmt ast.Statement) bool {
+	rule, ok := stmt.(ast.AtRule)
+	return ok && rule.Name == "try"
+}
@@ -0,0 +1,29 @@
+package interpreter
+
+import "testing"
+
+func TestExceptions(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"catch a thrown value", `main { @try { @throw "oops"; } @catch [e] { @return $e.value; } }`, `"oops"`},
+		{"catch an interpreter error", `main { @try { @return 1 / 0; } @catch [e] { @return $e.message; } }`, `"division by zero"`},
+		{"error span", "main {\n  @try {\n    @throw 1;\n  } @catch [e] { @return ($e.span.line, $e.span.column); }\n}", "[3, 5]"},
+		{"errors thrown from a call", `boom { @throw (a: 1); } main { @try { --x: boom(); } @catch [e] { @return map-get($e.value, "a"); } }`, "1"},
+		{"stack", `boom { @throw 1; } main { @try { --x: boom(); } @catch [e] { @return length($e.stack); } }`, "1"},
+		{"rethrow keeps the value", `main { @try { @try { @throw 7; } @catch [e] { @throw $e; } } @catch [e] { @return $e.value; } }`, "7"},
+		{"finally runs", `main { --l: []; @try { --l: append($l, 1); } @finally { --l: append($l, 2); } @return $l; }`, "[1, 2]"},
+		{"finally wins", `main { @try { @return 1; } @finally { @return 2; } }`, "2"},
+		{"catch assigns the enclosing rule", `main { --n: 0; @try { @throw 1; } @catch [e] { --n: $e.value; } @return $n; }`, "1"},
+		{"the error shadows a constant", `main { --e: 1 !important; @try { @throw 2; } @catch [e] { @return $e.value; } }`, "2"},
+		{"a user error type is a plain value", `@type error[message][value][span][stack]; main { @try { @throw error("a", "b", 1, []); } @catch [e] { @return $e.message; } }`, `"error(message: \"a\", value: \"b\", span: 1, stack: [])"`},
+	})
+}
+
+func TestExceptionErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"uncaught", "main {\n  @throw \"oops\";\n}", "oops", 2},
+		{"the error stays in the catch", "main {\n  @try { @throw 1; } @catch [e] { }\n  @return $e;\n}", "variable e not found", 3},
+		{"try needs a handler", "main {\n  @try { }\n}", "must be followed by a catch or a finally rule", 2},
+		{"one name", "main {\n  @try { @throw 1; } @catch [a][b] { }\n}", "bind the error to exactly one name", 2},
+		{"lone catch", "main {\n  @catch [e] { }\n}", "must be preceded by a try rule", 2},
+	})
+}
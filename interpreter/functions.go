@@ -0,0 +1,116 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/lexer"
+)
+
+// FunctionValue is a function used as a value, e.g. `fn(factorial)`. It
+// holds the clauses visible where it was referenced, each with the
+// environment it closes over.
+type FunctionValue struct {
+	Name    string
+	Clauses []Closure
+	Span    lexer.Span
+}
+
+func (f FunctionValue) IsValue() {}
+
+func (f FunctionValue) GetSpan() lexer.Span {
+	return f.Span
+}
+
+func (f FunctionValue) String() string {
+	return fmt.Sprintf("fn(%s)", f.Name)
+}
+
+func (f FunctionValue) equals(other FunctionValue) bool {
+	if len(f.Clauses) != len(other.Clauses) {
+		return false
+	}
+	for i, clause := range f.Clauses {
+		if clause.Env != other.Clauses[i].Env || clause.Rule.Span != other.Clauses[i].Rule.Span {
+			return false
+		}
+	}
+	return f.Name == other.Name
+}
+
+func functionValue(name string, span lexer.Span, env *Environment) (ast.Value, error) {
+	clauses, err := env.genFn(name)
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+	return FunctionValue{Name: name, Clauses: clauses, Span: span}, nil
+}
+
+// evalAnonymousRule turns a function literal into a closure over env.
+func evalAnonymousRule(value ast.AnonymousRule, env *Environment) (ast.Value, error) {
+	err := verifySelector(value.Selector)
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	rule := ast.Rule{
+		Selector: value.Selector,
+		Body:     value.Body,
+		Span:     value.Span,
+	}
+	return FunctionValue{
+		Name:    "anonymous",
+		Clauses: []Closure{{Rule: rule, Env: env}},
+		Span:    value.Span,
+	}, nil
+}
+
+// evalFnRefCall evaluates `fn(factorial)`, which turns the rule named by its
+// bare argument into a value.
+func evalFnRefCall(call ast.FunctionCall, env *Environment) (ast.Value, error) {
+	if len(call.Parameters) != 1 {
+		return ast.NilValue{}, fmt.Errorf("expected 1 parameter, got %d", len(call.Parameters))
+	}
+
+	id, ok := call.Parameters[0].(ast.Identifier)
+	if !ok {
+		return ast.NilValue{}, runtimeError(call.Parameters[0].GetSpan(), "fn expects the name of a function")
+	}
+	return functionValue(id.Name, call.Span, env)
+}
+
+// evalCallCall evaluates `call($f, args...)`.
+func evalCallCall(call ast.FunctionCall, env *Environment) (ast.Value, error) {
+	if len(call.Parameters) == 0 {
+		return ast.NilValue{}, fmt.Errorf("call expects a function to call")
+	}
+
+	val, err := evalValue(call.Parameters[0], env)
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	fn, ok := val.(FunctionValue)
+	if !ok {
+		return ast.NilValue{}, runtimeError(call.Parameters[0].GetSpan(), "cannot call a %s", getValueType(val))
+	}
+
+	params := make([]ast.Value, len(call.Parameters)-1)
+	for i, param := range call.Parameters[1:] {
+		params[i], err = evalValue(param, env)
+		if err != nil {
+			return ast.NilValue{}, err
+		}
+	}
+
+	return callFunctionValue(fn, params, call.Span)
+}
+
+// callFunctionValue calls fn, recording it on the stack like a direct call.
+func callFunctionValue(fn FunctionValue, params []ast.Value, callSite lexer.Span) (ast.Value, error) {
+	res, err := callFn(fn.Clauses, params, callSite)
+	if err != nil {
+		return ast.NilValue{}, pushFrame(err, fn.Name, callSite)
+	}
+	return res, nil
+}
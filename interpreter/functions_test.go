@@ -0,0 +1,26 @@
+package interpreter
+
+import "testing"
+
+func TestFunctions(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"fn", `double[x] { @return $x * 2; } main { --f: fn(double); @return call($f, 4); }`, "8"},
+		{"bare names", `double[x] { @return $x * 2; } main { --f: double; @return call($f, 4); }`, "8"},
+		{"every clause comes along", `f[x:int] { @return "int"; } f[x] { @return "any"; } main { --g: fn(f); @return (call($g, 1), call($g, "a")); }`, `["int", "any"]`},
+		{"passed as arguments", `twice[f][x] { @return call($f, call($f, $x)); } inc[x] { @return $x + 1; } main { @return twice(inc, 1); }`, "3"},
+		{"returned from rules", `adder[n] { add[x] { @return $x + $n; } @return fn(add); } main { --add2: adder(2); @return call($add2, 3); }`, "5"},
+		{"closures keep their own scope", `adder[n] { add[x] { @return $x + $n; } @return fn(add); } main { --a: adder(1); --b: adder(10); @return (call($a, 1), call($b, 1)); }`, "[2, 11]"},
+		{"equality", `f { } g { } main { @return (fn(f) == fn(f), fn(f) == fn(g)); }`, "[true, false]"},
+		{"printing", `f { } main { @return to-string(fn(f)); }`, `"fn(f)"`},
+		{"type-of", `f { } main { @return (type-of(fn(f)), is-function(fn(f))); }`, `["function", true]`},
+	})
+}
+
+func TestFunctionErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"call a non function", "main {\n  @return call(1);\n}", "cannot call a int", 2},
+		{"fn of an unknown rule", "main {\n  @return fn(nope);\n}", "function nope not found", 2},
+		{"fn of a value", "main {\n  @return fn(1);\n}", "fn expects the name of a function", 2},
+		{"nested rules are local", "outer { inner { @return 1; } @return 0; }\nmain {\n  @return inner();\n}", "function inner not found", 3},
+	})
+}
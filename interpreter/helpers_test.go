@@ -0,0 +1,79 @@
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shreyassanthu77/cisp/lexer"
+	"github.com/shreyassanthu77/cisp/parser"
+)
+
+// run evaluates the program src and returns what its main rule returned,
+// formatted the way it's printed inside a list.
+func run(t *testing.T, src string) (string, error) {
+	t.Helper()
+	program, diags := parser.New(lexer.New(src)).Parse()
+	if len(diags) > 0 {
+		t.Fatalf("unexpected parse error: %s", diags[0].Message)
+	}
+	res, err := Eval(program)
+	if err != nil {
+		return "", err
+	}
+	return stringifyNested(res), nil
+}
+
+type valueTest struct {
+	name string
+	src  string
+	want string
+}
+
+func runValueTests(t *testing.T, tests []valueTest) {
+	t.Helper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := run(t, test.src)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+// errorTest expects src to fail with an error containing want, located on
+// line when it isn't 0.
+type errorTest struct {
+	name string
+	src  string
+	want string
+	line int
+}
+
+func runErrorTests(t *testing.T, tests []errorTest) {
+	t.Helper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := run(t, test.src)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got %q, want it to contain %q", err, test.want)
+			}
+			if test.line == 0 {
+				return
+			}
+			rtErr, ok := err.(*RuntimeError)
+			if !ok {
+				t.Fatalf("got %T, want a runtime error", err)
+			}
+			if rtErr.Span.Start.Line != test.line {
+				t.Errorf("error is on line %d, want %d", rtErr.Span.Start.Line, test.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package interpreter
+
+import "testing"
+
+func TestHigherOrder(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"map", `main { @return map((1, 2, 3), &[v] { @return $v * 2; }); }`, "[2, 4, 6]"},
+		{"filter", `main { @return filter((1, 2, 3, 4), &[v] { @return $v % 2 == 0; }); }`, "[2, 4]"},
+		{"reduce", `main { @return reduce((1, 2, 3), &[acc][v] { @return $acc + $v; }, 0); }`, "6"},
+		{"reduce from nil", `main { @return reduce((1, 2), &[acc][x] { @return $acc; }, ()); }`, "nil"},
+		{"sort-by", `main { @return sort-by(("bb", "a", "ccc"), &[s] { @return str-length($s); }); }`, `["a", "bb", "ccc"]`},
+		{"sort-by is stable", `main { @return sort-by((3, 1, 2, 4), &[n] { @return $n % 2; }); }`, "[2, 4, 3, 1]"},
+		{"named rules", `double[x] { @return $x * 2; } main { @return map((1, 2), double); }`, "[2, 4]"},
+		{"anonymous rule without parameters", `main { --f: &{ @return 1; }; @return call($f); }`, "1"},
+		{"anonymous rules close over their scope", `main { --n: 10; @return map((1, 2), &[v] { @return $v + $n; }); }`, "[11, 12]"},
+	})
+}
+
+func TestHigherOrderErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"filter needs booleans", "main {\n  @return filter((1, 2), &[v] { @return 1; });\n}", "filter function must return a boolean", 2},
+		{"sort-by keys of one type", "main {\n  @return sort-by((1, \"a\"), &[v] { @return $v; });\n}", "sort-by keys must all be numbers or all be strings", 2},
+		{"map needs a function", "main {\n  @return map((1, 2), 3);\n}", "parameter f expects a function", 2},
+	})
+}
+
+// The calls a builtin makes to the function it's given are located at the
+// call of the builtin.
+func TestHigherOrderCallSites(t *testing.T) {
+	for _, builtin := range []string{"map", "filter", "sort-by"} {
+		t.Run(builtin, func(t *testing.T) {
+			src := "main {\n  --l: " + builtin + "((1, 2),\n    &[x] { @return $x / 0; });\n}"
+			_, err := run(t, src)
+			rtErr, ok := err.(*RuntimeError)
+			if !ok {
+				t.Fatalf("got %v, want a runtime error", err)
+			}
+			if len(rtErr.Stack) < 2 {
+				t.Fatalf("got the stack %v, want a frame for the function and %s", rtErr.Stack, builtin)
+			}
+			fn, native := rtErr.Stack[0], rtErr.Stack[1]
+			if native.Name != builtin {
+				t.Fatalf("got the frame %s, want %s", native.Name, builtin)
+			}
+			if fn.CallSite != native.CallSite {
+				t.Errorf("the function is called at %v, want the call of %s at %v", fn.CallSite.Start, builtin, native.CallSite.Start)
+			}
+		})
+	}
+
+	t.Run("reduce", func(t *testing.T) {
+		_, err := run(t, "main {\n  --l: reduce((1, 2), &[a][b] { @return $a / 0; }, 1);\n}")
+		rtErr, ok := err.(*RuntimeError)
+		if !ok || len(rtErr.Stack) < 2 {
+			t.Fatalf("got %v, want a runtime error with a stack", err)
+		}
+		if line := rtErr.Stack[0].CallSite.Start.Line; line != 2 {
+			t.Errorf("the function is called on line %d, want 2", line)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package interpreter
+
+import "testing"
+
+func TestImportant(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"read", `main { --x: 5 !important; @return $x; }`, "5"},
+		{"global", `--g: 5 !important; main { @return $g; }`, "5"},
+		{"parameters shadow constants", `--g: 5 !important; f[g] { @return $g; } main { @return f(1); }`, "1"},
+		{"builtin constants", `main { @return $math.pi > 3; }`, "true"},
+	})
+}
+
+func TestImportantErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"reassign", "main {\n  --x: 5 !important;\n  --x: 6;\n}", "cannot reassign --x, it was declared !important", 3},
+		{"redeclare", "main {\n  --x: 5 !important;\n  --x: 5 !important;\n}", "cannot reassign --x", 3},
+		{"reassign a global", "--g: 5 !important;\nmain {\n  --g: 1;\n}", "cannot reassign --g", 3},
+		{"reassign a builtin constant", "main {\n  --math.pi: 3;\n}", "cannot reassign --math.pi", 2},
+	})
+}
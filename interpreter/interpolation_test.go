@@ -0,0 +1,25 @@
+package interpreter
+
+import "testing"
+
+func TestInterpolation(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"expression", `main { --n: 21; @return "Result: #{$n * 2}"; }`, `"Result: 42"`},
+		{"several parts", `main { @return "#{1} + #{2} = #{1 + 2}"; }`, `"1 + 2 = 3"`},
+		{"strings aren't quoted", `main { --s: "x"; @return "[#{$s}]"; }`, `"[x]"`},
+		{"formatted like print", `main { @return "#{(1, "a")} #{(k: 1)} #{true} #{1.5}"; }`, `"[1, \"a\"] (k: 1) true 1.5"`},
+		{"calls", `double[x] { @return $x * 2; } main { @return "#{double(2)}"; }`, `"4"`},
+		{"strings inside", `main { @return "a #{"b" + "c"} d"; }`, `"a bc d"`},
+		{"nested", `main { @return "a #{"b #{1 + 1}"}"; }`, `"a b 2"`},
+		{"escaped", `main { @return "\#{1}"; }`, `"#{1}"`},
+		{"escapes around", `main { @return "\"#{1}\"\n"; }`, "\"\\\"1\\\"\\n\""},
+		{"records", `@type p[x]; main { @return "#{p(1)}"; }`, `"p(x: 1)"`},
+	})
+}
+
+func TestInterpolationErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"unknown variable", "main {\n  @return \"a #{$nope}\";\n}", "variable nope not found", 2},
+		{"errors inside", "main {\n  @return \"a #{1 / 0}\";\n}", "division by zero", 2},
+	})
+}
@@ -0,0 +1,29 @@
+package interpreter
+
+import "testing"
+
+func TestLists(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"parenthesized", `main { @return (1, "a", true); }`, `[1, "a", true]`},
+		{"bracketed", `main { @return [1 2 3]; }`, "[1, 2, 3]"},
+		{"empty", `main { @return []; }`, "[]"},
+		{"nested", `main { @return ((1, 2), [3]); }`, "[[1, 2], [3]]"},
+		{"nth counts from 1", `main { @return nth((10, 20, 30), 2); }`, "20"},
+		{"nth from the end", `main { @return nth((10, 20, 30), -1); }`, "30"},
+		{"length", `main { @return (length((1, 2, 3)), length([])); }`, "[3, 0]"},
+		{"append", `main { @return append((1, 2), 3); }`, "[1, 2, 3]"},
+		{"append nil", `main { @return append((1, 2), ()); }`, "[1, 2, nil]"},
+		{"concat", `main { @return concat((1, 2), (3, 4)); }`, "[1, 2, 3, 4]"},
+		{"equality", `main { @return ((1, 2) == (1, 2), (1, 2) == (2, 1)); }`, "[true, false]"},
+	})
+}
+
+func TestListErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"nth out of range", "main {\n  @return nth((1, 2), 3);\n}", "index 3 is out of bounds for a list of length 2", 2},
+		{"nth of zero", "main {\n  @return nth((1, 2), 0);\n}", "index 0 is out of bounds", 2},
+		{"append to a non list", "main {\n  @return append(1, 2);\n}", "parameter list expects a list but got int", 2},
+		{"required typed parameter", "main {\n  @return str-length(());\n}", "parameter string is required", 2},
+		{"required list", "main {\n  @return length(());\n}", "parameter list is required", 2},
+	})
+}
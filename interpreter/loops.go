@@ -0,0 +1,247 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/lexer"
+)
+
+// BreakValue and ContinueValue are produced by `@break` and `@continue` and
+// travel up the statement lists until they reach the enclosing loop.
+type BreakValue struct {
+	Span lexer.Span
+}
+
+func (b BreakValue) IsValue() {}
+
+func (b BreakValue) GetSpan() lexer.Span {
+	return b.Span
+}
+
+type ContinueValue struct {
+	Span lexer.Span
+}
+
+func (c ContinueValue) IsValue() {}
+
+func (c ContinueValue) GetSpan() lexer.Span {
+	return c.Span
+}
+
+// isControlFlow reports whether v should stop the evaluation of the current
+// statement list.
+func isControlFlow(v ast.Value) bool {
+	switch v.(type) {
+	case ReturnValue, BreakValue, ContinueValue:
+		return true
+	}
+	return false
+}
+
+func loopVariable(v ast.Value) (string, error) {
+	deref, ok := v.(ast.VarianleDerefValue)
+	if !ok {
+		return "", fmt.Errorf("expected a loop variable like $i")
+	}
+	return deref.Variable.Name, nil
+}
+
+func isKeyword(v ast.Value, keywords ...string) bool {
+	id, ok := v.(ast.Identifier)
+	if !ok {
+		return false
+	}
+	for _, keyword := range keywords {
+		if id.Name == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// evalLoopBody runs one iteration of a loop in env, a block scope of its
+// own. It reports whether the loop should stop and, when it stops because of
+// `@return`, the value to return.
+func evalLoopBody(body []ast.Statement, env *Environment) (ast.Value, bool, error) {
+	res, err := evalStatementList(body, env)
+	if err != nil {
+		return ast.NilValue{}, true, err
+	}
+
+	switch res.(type) {
+	case ReturnValue:
+		return res, true, nil
+	case BreakValue:
+		return ast.NilValue{}, true, nil
+	}
+	return ast.NilValue{}, false, nil
+}
+
+func evalIntParam(v ast.Value, env *Environment) (int64, error) {
+	val, err := evalValue(v, env)
+	if err != nil {
+		return 0, err
+	}
+
+	n, ok := val.(ast.Int)
+	if !ok {
+		return 0, runtimeError(v.GetSpan(), "expected an int but got %s", getValueType(val))
+	}
+	return n.Value, nil
+}
+
+// evalForRule evaluates `@for $i from <start> through <end>`, which includes
+// end, and `@for $i from <start> to <end>`, which does not. It counts down
+// when start is bigger than end.
+func evalForRule(env *Environment, rule ast.AtRule) (ast.Value, error) {
+	params := rule.Parameters
+	if len(params) != 5 || !isKeyword(params[1], "from") || !isKeyword(params[3], "through", "to") {
+		return ast.NilValue{}, fmt.Errorf("for rules should look like @for $i from 1 through 10")
+	}
+
+	name, err := loopVariable(params[0])
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	start, err := evalIntParam(params[2], env)
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	end, err := evalIntParam(params[4], env)
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	step := int64(1)
+	if start > end {
+		step = -1
+	}
+	if isKeyword(params[3], "through") {
+		end += step
+	}
+
+	for i := start; i != end; i += step {
+		iteration := env.blockFork()
+		iteration.bindParam(name, ast.Int{Value: i})
+
+		res, stop, err := evalLoopBody(rule.Body, iteration)
+		if stop {
+			return res, err
+		}
+	}
+
+	return ast.NilValue{}, nil
+}
+
+func evalWhileRule(env *Environment, rule ast.AtRule) (ast.Value, error) {
+	if len(rule.Parameters) != 1 {
+		return ast.NilValue{}, fmt.Errorf("while rules should have exactly one parameter")
+	}
+
+	for {
+		condition, err := evalValue(rule.Parameters[0], env)
+		if err != nil {
+			return ast.NilValue{}, err
+		}
+
+		conditionResult, ok := condition.(ast.Boolean)
+		if !ok {
+			return ast.NilValue{}, fmt.Errorf("while rule condition must evaluate to a boolean")
+		}
+
+		if !conditionResult.Value {
+			return ast.NilValue{}, nil
+		}
+
+		res, stop, err := evalLoopBody(rule.Body, env.blockFork())
+		if stop {
+			return res, err
+		}
+	}
+}
+
+// evalEachRule evaluates `@each $x in a b c`, binding $x to every value
+// after `in` in turn. A single list after `in` is iterated item by item and
+// a single map entry by entry. With several variables, `@each $k, $v in $map`,
+// every item is destructured into them.
+func evalEachRule(env *Environment, rule ast.AtRule) (ast.Value, error) {
+	params := rule.Parameters
+	in := 0
+	for in < len(params) && !isKeyword(params[in], "in") {
+		in++
+	}
+
+	if in == 0 || in >= len(params)-1 {
+		return ast.NilValue{}, fmt.Errorf("each rules should look like @each $x in $values")
+	}
+
+	names := make([]string, in)
+	for i, param := range params[:in] {
+		name, err := loopVariable(param)
+		if err != nil {
+			return ast.NilValue{}, err
+		}
+		names[i] = name
+	}
+
+	items := make([]ast.Value, len(params)-in-1)
+	for i, param := range params[in+1:] {
+		var err error
+		items[i], err = evalValue(param, env)
+		if err != nil {
+			return ast.NilValue{}, err
+		}
+	}
+
+	if len(items) == 1 {
+		switch collection := items[0].(type) {
+		case ast.List:
+			items = collection.Values
+		case ast.Map:
+			items = make([]ast.Value, len(collection.Entries))
+			for i, entry := range collection.Entries {
+				items[i] = ast.List{Values: []ast.Value{entry.Key, entry.Value}}
+			}
+		}
+	}
+
+	for _, item := range items {
+		iteration := env.blockFork()
+		err := bindEachVariables(iteration, names, item)
+		if err != nil {
+			return ast.NilValue{}, err
+		}
+
+		res, stop, err := evalLoopBody(rule.Body, iteration)
+		if stop {
+			return res, err
+		}
+	}
+
+	return ast.NilValue{}, nil
+}
+
+func bindEachVariables(env *Environment, names []string, item ast.Value) error {
+	if len(names) == 1 {
+		env.bindParam(names[0], item)
+		return nil
+	}
+
+	list, ok := item.(ast.List)
+	if !ok {
+		return fmt.Errorf("cannot destructure a %s into %d variables", getValueType(item), len(names))
+	}
+
+	for i, name := range names {
+		var val ast.Value = ast.NilValue{}
+		if i < len(list.Values) {
+			val = list.Values[i]
+		}
+
+		env.bindParam(name, val)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package interpreter
+
+import "testing"
+
+func TestLoops(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"for through", `main { --l: []; @for $i from 1 through 3 { --l: append($l, $i); } @return $l; }`, "[1, 2, 3]"},
+		{"for to", `main { --l: []; @for $i from 1 to 3 { --l: append($l, $i); } @return $l; }`, "[1, 2]"},
+		{"for counts down", `main { --l: []; @for $i from 3 through 1 { --l: append($l, $i); } @return $l; }`, "[3, 2, 1]"},
+		{"while", `main { --i: 0; @while $i < 5 { --i: $i + 1; } @return $i; }`, "5"},
+		{"each over a list", `main { --n: 0; @each $x in (1, 2, 3) { --n: $n + $x; } @return $n; }`, "6"},
+		{"each over values", `main { --n: 0; @each $x in 1 2 3 { --n: $n + $x; } @return $n; }`, "6"},
+		{"each over a map", `main { --l: []; @each $k, $v in (a: 1, b: 2) { --l: append($l, $k + to-string($v)); } @return $l; }`, `["a1", "b2"]`},
+		{"break", `main { --n: 0; @for $i from 1 through 10 { @if $i > 3 { @break; } --n: $n + $i; } @return $n; }`, "6"},
+		{"continue", `main { --n: 0; @for $i from 1 through 5 { @if $i == 3 { @continue; } --n: $n + $i; } @return $n; }`, "12"},
+		{"return from a loop", `main { @each $x in (1, 2, 3) { @if $x == 2 { @return $x; } } @return 0; }`, "2"},
+		{"deep loops don't grow the stack", `main { --i: 0; @while $i < 100000 { --i: $i + 1; } @return $i; }`, "100000"},
+		{"important declarations in a loop body", `main { --n: 0; @for $i from 1 through 3 { --c: $i !important; --n: $n + $c; } @return $n; }`, "6"},
+		{"closures capture each iteration", `main { --fs: []; @each $x in (1, 2, 3) { --fs: append($fs, &{ @return $x; }); } @return map($fs, &[f] { @return call($f); }); }`, "[1, 2, 3]"},
+		{"rules defined in a loop body", `main { --fs: []; @each $x in (1, 2) { get { @return $x; } --fs: append($fs, fn(get)); } @return map($fs, &[f] { @return call($f); }); }`, "[1, 2]"},
+		{"variables declared in the body stay in it", `main { --x: 1; @for $i from 1 through 2 { --y: $i; } @return $x; }`, "1"},
+	})
+}
+
+func TestLoopScope(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"loop variable", "main {\n  @for $i from 1 through 2 { }\n  @return $i;\n}", "variable i not found", 3},
+		{"each variable", "main {\n  @each $x in (1, 2) { }\n  @return $x;\n}", "variable x not found", 3},
+		{"body variable", "main {\n  @for $i from 1 through 2 { --y: $i; }\n  @return $y;\n}", "variable y not found", 3},
+		{"bad for", "main {\n  @for $i in 1 { }\n}", "for rules should look like", 2},
+	})
+}
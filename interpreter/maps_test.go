@@ -0,0 +1,30 @@
+package interpreter
+
+import "testing"
+
+func TestMaps(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"literal", `main { @return (a: 1, "b c": 2); }`, "(a: 1, b c: 2)"},
+		{"map-get", `main { @return map-get((a: 1, b: 2), "b"); }`, "2"},
+		{"map-get of a missing key", `main { @return map-get((a: 1), "b"); }`, "nil"},
+		{"map-get with a variable", `main { --k: "a"; @return map-get((a: 1), $k); }`, "1"},
+		{"map-get nil key", `main { @return map-get((a: 1), ()); }`, "nil"},
+		{"map-has-key", `main { @return (map-has-key((a: 1), "a"), map-has-key((a: 1), "b")); }`, "[true, false]"},
+		{"map-has-key nil key", `main { @return map-has-key((a: 1), ()); }`, "false"},
+		{"map-merge", `main { @return map-merge((a: 1, b: 2), (b: 3, c: 4)); }`, "(a: 1, b: 3, c: 4)"},
+		{"map-keys", `main { @return map-keys((a: 1, b: 2)); }`, `["a", "b"]`},
+		{"map-values", `main { @return map-values((a: 1, b: 2)); }`, "[1, 2]"},
+		{"each", `main { --l: []; @each $k, $v in (a: 1, b: 2) { --l: append($l, $k + to-string($v)); } @return $l; }`, `["a1", "b2"]`},
+		{"equality ignores order", `main { @return (a: 1, b: 2) == (b: 2, a: 1); }`, "true"},
+		{"call with a space before (", `double[x] { @return $x * 2; } main { @return double (4); }`, "8"},
+		{"at-rule keywords are not calls", `main { --n: 0; @each $x in (1, 2) { --n: $n + $x; } @return $n; }`, "3"},
+		{"keywords of other at-rules are calls", `to[x] { @return $x; } main { @return to(1); }`, "1"},
+	})
+}
+
+func TestMapErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"bare keys aren't strings in calls", "main {\n  @return map-get((a: 1), a);\n}", "Literal Identifiers are not allowed", 2},
+		{"map-get of a list", "main {\n  @return map-get((1, 2), \"a\");\n}", "parameter map expects a map but got list", 2},
+	})
+}
@@ -0,0 +1,246 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shreyassanthu77/cisp/ast"
+)
+
+// matchCase is one `@case` of a `@match`, `@case 1, 2 if $flag { }` has two
+// alternative patterns and a guard.
+type matchCase struct {
+	Patterns []ast.Value
+	Guard    ast.Value
+	Rule     ast.AtRule
+}
+
+func parseMatchCase(rule ast.AtRule) (matchCase, error) {
+	c := matchCase{Rule: rule}
+	for i, param := range rule.Parameters {
+		if isKeyword(param, "if") {
+			if i == 0 || i != len(rule.Parameters)-2 {
+				return matchCase{}, runtimeError(param.GetSpan(), "expected @case <pattern> if <condition>")
+			}
+			c.Guard = rule.Parameters[i+1]
+			break
+		}
+		c.Patterns = append(c.Patterns, param)
+	}
+
+	if len(c.Patterns) == 0 {
+		return matchCase{}, fmt.Errorf("case rules need a pattern")
+	}
+	return c, nil
+}
+
+// matchCases splits the body of a `@match` into its cases and its `@default`
+// rule, which has to come last.
+func matchCases(rule ast.AtRule) ([]matchCase, *ast.AtRule, error) {
+	cases := []matchCase{}
+	var fallback *ast.AtRule
+	for _, stmt := range rule.Body {
+		at, ok := stmt.(ast.AtRule)
+		if !ok || (at.Name != "case" && at.Name != "default") {
+			return nil, nil, runtimeError(stmt.GetSpan(), "only @case and @default rules can be used inside @match")
+		}
+
+		if fallback != nil {
+			return nil, nil, runtimeError(at.Span, "@default must be the last rule of a @match")
+		}
+
+		if at.Name == "default" {
+			if len(at.Parameters) != 0 {
+				return nil, nil, runtimeError(at.Span, "default rules don't take parameters")
+			}
+			fallback = &at
+			continue
+		}
+
+		c, err := parseMatchCase(at)
+		if err != nil {
+			return nil, nil, withSpan(err, at.Span)
+		}
+		cases = append(cases, c)
+	}
+	return cases, fallback, nil
+}
+
+// isOfType reports whether value has the type a pattern like `int` names.
+// Unlike parameters, `float` doesn't match ints here.
+func isOfType(typ string, value ast.Value) bool {
+	if typ == "float" {
+		return getValueType(value) == val_type_float
+	}
+	_, err := checkType("", typ, value)
+	return err == nil
+}
+
+// matchPattern reports whether value matches pattern, recording the
+// variables the pattern binds in bindings.
+//
+//   - `$x` matches anything and binds it to x, `_` matches anything
+//   - `int`, `string`, ... match a type, `point` matches a record type and
+//     `direction` any variant of an enum
+//   - `(1, $x)` matches a list of the same length element by element, when
+//     the list is longer its trailing variable binds the rest of it, so
+//     `(1, $rest)` matches `(1, 2, 3)` with `$rest` being `(2, 3)`
+//   - anything else, `$direction.north` included, is evaluated and compared
+//     with ==
+func matchPattern(pattern ast.Value, value ast.Value, bindings map[string]ast.Value, env *Environment) (bool, error) {
+	switch pattern := pattern.(type) {
+	case ast.VarianleDerefValue:
+		if !strings.Contains(pattern.Variable.Name, ".") {
+			bindings[pattern.Variable.Name] = value
+			return true, nil
+		}
+	case ast.Identifier:
+		if pattern.Name == "_" {
+			return true, nil
+		}
+		if isKnownType(pattern.Name) {
+			return isOfType(pattern.Name, value), nil
+		}
+		switch value := value.(type) {
+		case RecordValue:
+			return value.Name() == pattern.Name, nil
+		case EnumValue:
+			return value.Name() == pattern.Name, nil
+		}
+		return false, nil
+	case ast.List:
+		list, ok := value.(ast.List)
+		if !ok {
+			return false, nil
+		}
+		return matchListPattern(pattern, list, bindings, env)
+	}
+
+	expected, err := evalValue(pattern, env)
+	if err != nil {
+		return false, err
+	}
+	return valuesEqual(expected, value), nil
+}
+
+func matchListPattern(pattern ast.List, list ast.List, bindings map[string]ast.Value, env *Environment) (bool, error) {
+	items := pattern.Values
+	values := list.Values
+	if len(values) > len(items) && len(items) > 0 {
+		last := items[len(items)-1]
+		rest, ok := last.(ast.VarianleDerefValue)
+		if !ok || strings.Contains(rest.Variable.Name, ".") {
+			return false, nil
+		}
+		items = items[:len(items)-1]
+		bindings[rest.Variable.Name] = ast.List{Values: values[len(items):], Span: list.Span}
+		values = values[:len(items)]
+	}
+
+	if len(values) != len(items) {
+		return false, nil
+	}
+	for i, item := range items {
+		ok, err := matchPattern(item, values[i], bindings, env)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matches reports whether value is accepted by one of the patterns of c and
+// its guard, and returns the variables bound by the pattern that matched.
+func (c matchCase) matches(value ast.Value, env *Environment) (map[string]ast.Value, bool, error) {
+	for _, pattern := range c.Patterns {
+		bindings := map[string]ast.Value{}
+		ok, err := matchPattern(pattern, value, bindings, env)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			continue
+		}
+
+		if c.Guard == nil {
+			return bindings, true, nil
+		}
+
+		guardEnv := env.fork()
+		for name, val := range bindings {
+			guardEnv.bindParam(name, val)
+		}
+
+		cond, err := evalValue(c.Guard, guardEnv)
+		if err != nil {
+			return nil, false, err
+		}
+
+		holds, isBool := cond.(ast.Boolean)
+		if !isBool {
+			return nil, false, runtimeError(c.Guard.GetSpan(), "case guards must evaluate to a boolean")
+		}
+		if holds.Value {
+			return bindings, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// evalMatchRule runs the first `@case` that matches the value of the
+// `@match`, or its `@default`. The result of the case body is returned as
+// is, so `@return` inside a case returns from the enclosing rule.
+func evalMatchRule(env *Environment, rule ast.AtRule) (ast.Value, error) {
+	if len(rule.Parameters) != 1 {
+		return ast.NilValue{}, fmt.Errorf("match rules should have exactly one parameter")
+	}
+
+	value, err := evalValue(rule.Parameters[0], env)
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	cases, fallback, err := matchCases(rule)
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	for _, c := range cases {
+		bindings, ok, err := c.matches(value, env)
+		if err != nil {
+			return ast.NilValue{}, err
+		}
+		if !ok {
+			continue
+		}
+
+		caseEnv := env.blockFork()
+		for name, val := range bindings {
+			caseEnv.bindParam(name, val)
+		}
+		return evalStatementList(c.Rule.Body, caseEnv)
+	}
+
+	if fallback != nil {
+		return evalStatementList(fallback.Body, env.blockFork())
+	}
+
+	return ast.NilValue{}, fmt.Errorf("no @case matches %s", stringifyNested(value))
+}
+
+// evalMatchValue evaluates `@match` used as a value, the value is whatever
+// the matching case `@return`s.
+func evalMatchValue(match ast.Match, env *Environment) (ast.Value, error) {
+	res, err := evalMatchRule(env, match.Rule)
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	switch res := res.(type) {
+	case ReturnValue:
+		return res.Value, nil
+	case BreakValue, ContinueValue:
+		return ast.NilValue{}, runtimeError(res.GetSpan(), "@break and @continue can't leave a @match used as a value")
+	}
+	return ast.NilValue{}, nil
+}
@@ -0,0 +1,48 @@
+package interpreter
+
+import "testing"
+
+const signRule = `
+sign[n] {
+  @return @match $n {
+    @case 0 { @return "zero"; }
+    @case int if $n > 0 { @return "positive"; }
+    @case int, float { @return "negative"; }
+    @default { @return "nan"; }
+  };
+}
+`
+
+func TestMatch(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"literal", signRule + `main { @return sign(0); }`, `"zero"`},
+		{"guard", signRule + `main { @return sign(3); }`, `"positive"`},
+		{"alternatives", signRule + `main { @return (sign(-3), sign(-1.5)); }`, `["negative", "negative"]`},
+		{"default", signRule + `main { @return sign("x"); }`, `"nan"`},
+		{"binding", `main { @match 5 { @case $x { @return $x * 2; } } }`, "10"},
+		{"wildcard", `main { @match 5 { @case 1 { @return 1; } @case _ { @return 2; } } }`, "2"},
+		{"list of the same length", `main { @match (1, 2) { @case ($a, $b) { @return $a + $b; } } }`, "3"},
+		{"rest of a list", `main { @match (1, 2, 3) { @case (1, $rest) { @return $rest; } } }`, "[2, 3]"},
+		{"rest needs a trailing variable", `main { @match (1, 2, 3) { @case (1, 2) { @return 1; } @default { @return 2; } } }`, "2"},
+		{"shorter lists don't match", `main { @match (1) { @case (1, $rest) { @return 1; } @default { @return 2; } } }`, "2"},
+		{"record type", `@type point[x][y]; main { @match point(1, 2) { @case point { @return "point"; } } }`, `"point"`},
+		{"enum variant", `@enum dir[up][down]; main { @match $dir.down { @case $dir.up { @return 1; } @case $dir.down { @return 2; } } }`, "2"},
+		{"enums are declared before globals", "--d: $dir.up; @enum dir[up][down]; main { @return $d; }", "dir.up"},
+		{"enum type", `@enum dir[up][down]; main { @match $dir.up { @case int { @return 1; } @case dir { @return 2; } } }`, "2"},
+		{"guard sees the binding", `main { @match 4 { @case $x if $x > 5 { @return "big"; } @case $x { @return "small"; } } }`, `"small"`},
+		{"bindings stay in the case", `main { --x: 1; @match 5 { @case $x { } } @return $x; }`, "1"},
+		{"bindings shadow important variables", `main { --x: 1 !important; @match 5 { @case $x { @return $x; } } }`, "5"},
+		{"case bodies assign the enclosing rule", `main { --n: 0; @match 5 { @case $x { --n: $x; } } @return $n; }`, "5"},
+	})
+}
+
+func TestMatchErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"no case matches", "main {\n  @match 1 { @case 2 { } }\n}", "no @case matches 1", 2},
+		{"only cases", "main {\n  @match 1 { --x: 1; }\n}", "only @case and @default", 2},
+		{"default comes last", "main {\n  @match 1 { @default { } @case 1 { } }\n}", "@default must be the last rule", 2},
+		{"guards are booleans", "main {\n  @match 1 { @case $x if 1 { } }\n}", "case guards must evaluate to a boolean", 2},
+		{"variants are constants", "@enum dir[up];\nmain {\n  --dir.up: 1;\n}", "declared !important", 3},
+		{"variants are plain names", "@enum dir[up:int];\nmain { }", "enum variants can't have a type", 1},
+	})
+}
@@ -0,0 +1,31 @@
+package interpreter
+
+import "testing"
+
+func TestMath(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"abs", `main { @return (math.abs(-3), math.abs(-1.5)); }`, "[3, 1.5]"},
+		{"min and max", `main { @return (math.min(3, 1, 2), math.max(3, 1.5)); }`, "[1, 3]"},
+		{"min of a list", `main { @return math.min((4, 2, 8)); }`, "2"},
+		{"clamp", `main { @return (math.clamp(0, 5, 3), math.clamp(0, -1, 3)); }`, "[3, 0]"},
+		{"floor and ceil", `main { @return (math.floor(1.5), math.ceil(1.5), math.floor(-1.5)); }`, "[1, 2, -2]"},
+		{"round halves up", `main { @return (math.round(2.5), math.round(-2.5), math.round(-2.6)); }`, "[3, -2, -3]"},
+		{"round just below a half", `main { @return math.round(0.49999999999999994); }`, "0"},
+		{"rounding an int", `main { @return math.round(7); }`, "7"},
+		{"sqrt", `main { @return math.sqrt(16); }`, "4"},
+		{"pow", `main { @return (math.pow(2, 10), math.pow(2, 0.5) > 1.41); }`, "[1024, true]"},
+		{"overflowing math.pow is a float", `main { @return math.pow(3, 40); }`, "1.2157665459056929e+19"},
+		{"constants", `main { @return ($math.pi > 3.14, $math.e < 2.72); }`, "[true, true]"},
+		{"seeded random", `main { --a: math.seed(7); --x: math.random(100); --b: math.seed(7); @return $x == math.random(100); }`, "true"},
+	})
+}
+
+func TestMathErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"negative sqrt", "main {\n  @return math.sqrt(-1);\n}", "square root of a negative number", 2},
+		{"round too big", "main {\n  @return math.round(10.0 ^ 300);\n}", "cannot round", 2},
+		{"floor too small", "main {\n  @return math.floor(-10.0 ^ 19);\n}", "cannot round -1e+19 to an int", 2},
+		{"ceil of the largest int", "main {\n  @return math.ceil(9223372036854775807.0);\n}", "cannot round", 2},
+		{"random limit", "main {\n  @return math.random(0);\n}", "the limit of random must be at least 1", 2},
+	})
+}
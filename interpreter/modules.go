@@ -0,0 +1,176 @@
+package interpreter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/loader"
+)
+
+// moduleCache holds the global scope of every module evaluated so far, a
+// module imported from several places is only evaluated once.
+type moduleCache map[*loader.Module]*Environment
+
+// isPrivate reports whether a rule or variable is hidden from the modules
+// importing the one it's declared in, like `_helper`.
+func isPrivate(name string) bool {
+	for _, part := range strings.Split(name, ".") {
+		if strings.HasPrefix(part, "_") {
+			return true
+		}
+	}
+	return false
+}
+
+// namespace returns the name the rules of a `@use` are accessed through,
+// `@use "lib/math.css" as m;` uses m and `@use "lib/math.css";` uses math.
+func namespace(rule ast.AtRule, path string) (string, error) {
+	switch len(rule.Parameters) {
+	case 1:
+		base := filepath.Base(path)
+		return strings.TrimSuffix(base, filepath.Ext(base)), nil
+	case 3:
+		name, ok := rule.Parameters[2].(ast.Identifier)
+		if isKeyword(rule.Parameters[1], "as") && ok {
+			return name.Name, nil
+		}
+	}
+	return "", fmt.Errorf("use rules should look like @use \"module.css\" as name")
+}
+
+// evalImport evaluates the module a top level `@use` or `@import` refers to
+// and makes its public rules visible in env.
+func evalImport(env *Environment, mod *loader.Module, rule ast.AtRule, cache moduleCache) error {
+	path, err := loader.ImportPath(rule)
+	if err != nil {
+		return err
+	}
+
+	imported, ok := mod.Imports[path]
+	if !ok {
+		return fmt.Errorf("module %q was not loaded", path)
+	}
+
+	modEnv, err := evalModule(imported, cache)
+	if err != nil {
+		return err
+	}
+
+	if rule.Name == "import" {
+		if len(rule.Parameters) != 1 {
+			return fmt.Errorf("import rules should look like @import \"module.css\"")
+		}
+		return env.importAll(modEnv)
+	}
+
+	ns, err := namespace(rule, path)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := env.Modules[ns]; ok {
+		return fmt.Errorf("a module is already used as %s", ns)
+	}
+	env.Modules[ns] = modEnv
+	return nil
+}
+
+// importAll copies the public rules and variables of mod into e, the rules
+// keep running in the scope of their own module. The main rule of a module
+// only runs when the module is the program, and a rule e defines itself
+// with the same signature shadows the imported one.
+func (e *Environment) importAll(mod *Environment) error {
+	for name, clauses := range mod.Funcs {
+		if isPrivate(name) || name == "main" {
+			continue
+		}
+		for _, clause := range clauses {
+			if !e.definesLocally(e.Funcs[name], clause.Rule) {
+				e.Funcs[name] = addClause(e.Funcs[name], clause)
+			}
+		}
+	}
+
+	for name, clauses := range mod.Namespaced {
+		for _, clause := range clauses {
+			if isPrivate(clause.Rule.Selector.PathString()) || e.definesLocally(e.Namespaced[name], clause.Rule) {
+				continue
+			}
+			e.Namespaced[name] = addClause(e.Namespaced[name], clause)
+		}
+	}
+
+	for name, val := range mod.Vars {
+		if isPrivate(name) {
+			continue
+		}
+
+		var err error
+		if mod.Important[name] {
+			err = e.setImportantVar(name, val)
+		} else {
+			err = e.setVar(name, val)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// definesLocally reports whether clauses holds an unguarded rule declared in
+// e that accepts the same calls as rule.
+func (e *Environment) definesLocally(clauses []Closure, rule ast.Rule) bool {
+	signature := rule.Selector.Signature()
+	for _, clause := range clauses {
+		selector := clause.Rule.Selector
+		if clause.Env == e && len(selector.PseudoClasses) == 0 && selector.Signature() == signature {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Environment) getModule(ns string) (*Environment, bool) {
+	mod, ok := e.Modules[ns]
+	if !ok && e.Parent != nil {
+		return e.Parent.getModule(ns)
+	}
+	return mod, ok
+}
+
+// genModuleFn resolves `m.sqrt` to the public rule sqrt of the module used
+// as m in this scope.
+func (e *Environment) genModuleFn(name string) ([]Closure, bool) {
+	ns, rest, ok := strings.Cut(name, ".")
+	if !ok {
+		return nil, false
+	}
+
+	mod, ok := e.Modules[ns]
+	if !ok || isPrivate(rest) {
+		return nil, false
+	}
+
+	fn, ok := mod.Funcs[rest]
+	if !ok {
+		fn, ok = mod.genNamespacedFn(rest)
+	}
+	return fn, ok
+}
+
+// getModuleVar returns the public variable name of the module used as ns.
+func (e *Environment) getModuleVar(ns string, name string) (ast.Value, error) {
+	mod, ok := e.getModule(ns)
+	if !ok {
+		return ast.NilValue{}, fmt.Errorf("variable %s not found", ns)
+	}
+
+	val, ok := mod.Vars[name]
+	if !ok || isPrivate(name) {
+		return ast.NilValue{}, fmt.Errorf("module %s has no variable %s", ns, name)
+	}
+	return val, nil
+}
@@ -0,0 +1,137 @@
+package interpreter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shreyassanthu77/cisp/loader"
+)
+
+// runFiles writes files to a temporary directory and runs its main.css.
+func runFiles(t *testing.T, files map[string]string, searchPath ...string) (string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(src), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, path := range searchPath {
+		searchPath[i] = filepath.Join(dir, path)
+	}
+	mod, diags, err := loader.New(searchPath...).Load(filepath.Join(dir, "main.css"))
+	if err != nil || len(diags) > 0 {
+		t.Fatalf("failed to load the program: %v %v", err, diags)
+	}
+
+	res, err := EvalModule(mod)
+	if err != nil {
+		return "", err
+	}
+	return stringifyNested(res), nil
+}
+
+func TestModules(t *testing.T) {
+	lib := `
+		--pi: 3 !important;
+		--_secret: 42;
+		area[r] { @return $pi * $r * $r; }
+		_helper { @return 1; }
+		greet[x] { @return "lib " + $x; }
+	`
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"use", map[string]string{"lib.css": lib, "main.css": `@use "lib.css" as m; main { @return (m.area(2), $m.pi); }`}, "[12, 3]"},
+		{"use without a name", map[string]string{"lib.css": lib, "main.css": `@use "lib.css"; main { @return lib.area(1); }`}, "3"},
+		{"import", map[string]string{"lib.css": lib, "main.css": `@import "lib.css"; main { @return (area(1), $pi); }`}, "[3, 3]"},
+		{"nested directories", map[string]string{
+			"a/lib.css":  `@use "../b/util.css" as u; twice[x] { @return u.double($x); }`,
+			"b/util.css": `double[x] { @return $x * 2; }`,
+			"main.css":   `@use "a/lib.css" as l; main { @return l.twice(4); }`,
+		}, "8"},
+		{"modules are evaluated once", map[string]string{
+			"shared.css": `@type box[v]; make { @return box(1); }`,
+			"a.css":      `@use "shared.css" as s; get { @return s.make(); }`,
+			"main.css":   `@use "shared.css" as s; @use "a.css" as a; main { @return s.make() == a.get(); }`,
+		}, "true"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := runFiles(t, test.files)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	t.Run("search path", func(t *testing.T) {
+		got, err := runFiles(t, map[string]string{
+			"vendor/lib.css": lib,
+			"main.css":       `@use "lib.css" as m; main { @return m.area(1); }`,
+		}, "vendor")
+		if err != nil || got != "3" {
+			t.Errorf("got %s %v, want 3", got, err)
+		}
+	})
+}
+
+func TestModuleErrors(t *testing.T) {
+	lib := `--_secret: 42; _helper { @return 1; }`
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"private rules", map[string]string{"lib.css": lib, "main.css": `@use "lib.css" as m; main { @return m._helper(); }`}, "function m._helper not found"},
+		{"private imported rules", map[string]string{"lib.css": lib, "main.css": `@import "lib.css"; main { @return _helper(); }`}, "function _helper not found"},
+		{"private variables", map[string]string{"lib.css": lib, "main.css": `@use "lib.css" as m; main { @return $m._secret; }`}, "_secret"},
+		{"namespace used twice", map[string]string{"lib.css": lib, "other.css": "", "main.css": `@use "lib.css" as m; @use "other.css" as m; main { }`}, "a module is already used as m"},
+		{"use inside a rule", map[string]string{"main.css": `main { @use "lib.css"; }`}, "use rules can only be used at the top level"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := runFiles(t, test.files)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got %v, want an error containing %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestLocalRulesShadowImports(t *testing.T) {
+	got, err := runFiles(t, map[string]string{
+		"lib.css": `
+			greet[x] { @return "lib " + $x; }
+			other[x] { @return "lib other"; }
+		`,
+		"main.css": `
+			@import "lib.css";
+			greet[x] { @return "local " + $x; }
+			main { @return (greet("a"), other(1)); }
+		`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := `["local a", "lib other"]`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
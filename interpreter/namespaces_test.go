@@ -0,0 +1,22 @@
+package interpreter
+
+import "testing"
+
+func TestNamespaces(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"child", `math > sqrt[x] { @return $x * 10; } main { @return math.sqrt(2); }`, "20"},
+		{"child under an outer namespace", `a > b[x] { @return $x; } main { @return x.a.b(1); }`, "1"},
+		{"descendant", `geo shape area[x] { @return $x; } main { @return (geo.shape.area(1), geo.x.shape.area(2)); }`, "[1, 2]"},
+		{"descendant through another namespace", `a b[x] { @return $x; } main { @return a.c.b(1); }`, "1"},
+		{"plain and namespaced rules with one name", `area[x] { @return "plain"; } geo area[x] { @return "geo"; } main { @return (area(1), geo.area(1)); }`, `["plain", "geo"]`},
+		{"the earlier of two matching rules wins", `a b[x] { @return "desc"; } a > b[x] { @return "child"; } main { @return a.b(1); }`, `"desc"`},
+	})
+}
+
+func TestNamespaceErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"child is a direct parent", "a > b[x] { @return $x; }\nmain {\n  @return a.x.b(1);\n}", "function a.x.b not found", 3},
+		{"namespaced rules need their namespace", "a > b[x] { @return $x; }\nmain {\n  @return b(1);\n}", "function b not found", 3},
+		{"every namespace of the path is needed", "geo shape area[x] { }\nmain {\n  @return geo.area(1);\n}", "function geo.area not found", 3},
+	})
+}
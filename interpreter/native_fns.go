@@ -2,6 +2,8 @@ package interpreter
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/shreyassanthu77/cisp/ast"
 	"github.com/shreyassanthu77/cisp/lexer"
@@ -10,7 +12,10 @@ import (
 type NativeFnCall struct {
 	Fn         ast.Identifier
 	Parameters []ast.Attreibute
-	Handler    func(env *Environment) (ast.Value, error)
+	// Variadic native functions receive their last argument, and any that
+	// follow it, as a list.
+	Variadic bool
+	Handler  func(env *Environment) (ast.Value, error)
 }
 
 func (n NativeFnCall) IsStatement() {}
@@ -29,6 +34,93 @@ func ruleFromNativeFnCall(n NativeFnCall) ast.Rule {
 	}
 }
 
+func isVariadic(rule ast.Rule) bool {
+	if len(rule.Body) != 1 {
+		return false
+	}
+	native, ok := rule.Body[0].(NativeFnCall)
+	return ok && native.Variadic
+}
+
+// takesArity reports whether rule can be called with n arguments.
+func takesArity(rule ast.Rule, n int) bool {
+	if isVariadic(rule) {
+		return n >= len(rule.Selector.Atrributes)
+	}
+	return n == len(rule.Selector.Atrributes)
+}
+
+// packArgs gathers the trailing arguments of a call to a variadic rule into
+// the list its last parameter receives.
+func packArgs(rule ast.Rule, params []ast.Value) []ast.Value {
+	if !isVariadic(rule) {
+		return params
+	}
+
+	n := len(rule.Selector.Atrributes) - 1
+	rest := ast.List{Values: append([]ast.Value{}, params[n:]...)}
+	return append(params[:n:n], rest)
+}
+
+// param describes a parameter of a native function, typ is one of the types
+// accepted by attribute annotations or empty for any value. Typed parameters
+// are required, untyped ones accept `()` as a value like any other.
+func param(name string, typ string) ast.Attreibute {
+	attr := ast.Attreibute{
+		Name: ast.Identifier{Name: name},
+		Type: ast.Identifier{Name: typ},
+	}
+	if typ == "" {
+		attr.Default = ast.NilValue{}
+	}
+	return attr
+}
+
+// stringify formats a value the way print shows it.
+func stringify(v ast.Value) string {
+	if str, ok := v.(ast.String); ok {
+		return str.Value
+	}
+	return stringifyNested(v)
+}
+
+// stringifyNested formats a value inside a collection, where strings are
+// quoted so that `("a", "b")` prints as `["a", "b"]`. Map keys are left
+// unquoted, like the bare keys of a `(key: value)` literal.
+func stringifyNested(v ast.Value) string {
+	switch v := v.(type) {
+	case ast.String:
+		return strconv.Quote(v.Value)
+	case ast.Int:
+		return fmt.Sprint(v.Value)
+	case ast.Float:
+		return fmt.Sprint(v.Value)
+	case ast.Boolean:
+		return fmt.Sprint(v.Value)
+	case ast.NilValue:
+		return "nil"
+	case ast.List:
+		items := make([]string, len(v.Values))
+		for i, item := range v.Values {
+			items[i] = stringifyNested(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case ast.Map:
+		entries := make([]string, len(v.Entries))
+		for i, entry := range v.Entries {
+			entries[i] = stringify(entry.Key) + ": " + stringifyNested(entry.Value)
+		}
+		return "(" + strings.Join(entries, ", ") + ")"
+	case RecordValue:
+		return v.String()
+	case EnumValue:
+		return v.String()
+	case FunctionValue:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
+
 var printFn = ruleFromNativeFnCall(NativeFnCall{
 	Fn: ast.Identifier{Name: "print"},
 	Parameters: []ast.Attreibute{
@@ -39,20 +131,7 @@ var printFn = ruleFromNativeFnCall(NativeFnCall{
 	},
 	Handler: func(env *Environment) (ast.Value, error) {
 		val, _ := env.getVar("value")
-		switch val := val.(type) {
-		case ast.String:
-			fmt.Println(val.Value)
-		case ast.Int:
-			fmt.Println(val.Value)
-		case ast.Float:
-			fmt.Println(val.Value)
-		case ast.Boolean:
-			fmt.Println(val.Value)
-		case ast.NilValue:
-			fmt.Println("nil")
-		default:
-			fmt.Println(val)
-		}
+		fmt.Println(stringify(val))
 		return ast.NilValue{}, nil
 	},
 })
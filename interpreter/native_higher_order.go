@@ -0,0 +1,136 @@
+package interpreter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shreyassanthu77/cisp/ast"
+)
+
+// getFunction reads a parameter that was declared with the function type.
+func getFunction(env *Environment, name string) FunctionValue {
+	val, _ := env.getVar(name)
+	return val.(FunctionValue)
+}
+
+var mapFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "map"},
+	Parameters: []ast.Attreibute{
+		param("list", "list"),
+		param("f", "function"),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		list := getList(env, "list")
+		f := getFunction(env, "f")
+
+		values := make([]ast.Value, len(list))
+		for i, item := range list {
+			val, err := callFunctionValue(f, []ast.Value{item}, env.CallSite)
+			if err != nil {
+				return ast.NilValue{}, err
+			}
+			values[i] = val
+		}
+		return ast.List{Values: values}, nil
+	},
+})
+
+var filterFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "filter"},
+	Parameters: []ast.Attreibute{
+		param("list", "list"),
+		param("f", "function"),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		list := getList(env, "list")
+		f := getFunction(env, "f")
+
+		values := []ast.Value{}
+		for _, item := range list {
+			val, err := callFunctionValue(f, []ast.Value{item}, env.CallSite)
+			if err != nil {
+				return ast.NilValue{}, err
+			}
+
+			keep, ok := val.(ast.Boolean)
+			if !ok {
+				return ast.NilValue{}, fmt.Errorf("filter function must return a boolean but returned %s", getValueType(val))
+			}
+			if keep.Value {
+				values = append(values, item)
+			}
+		}
+		return ast.List{Values: values}, nil
+	},
+})
+
+// reduce folds a list from the left, f is called with the accumulator and
+// the current item.
+var reduceFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "reduce"},
+	Parameters: []ast.Attreibute{
+		param("list", "list"),
+		param("f", "function"),
+		param("initial", ""),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		list := getList(env, "list")
+		f := getFunction(env, "f")
+		acc, _ := env.getVar("initial")
+
+		for _, item := range list {
+			var err error
+			acc, err = callFunctionValue(f, []ast.Value{acc, item}, env.CallSite)
+			if err != nil {
+				return ast.NilValue{}, err
+			}
+		}
+		return acc, nil
+	},
+})
+
+// sort-by sorts a list by the key f returns for every item. The sort is
+// stable and keys must all be numbers or all be strings.
+var sortByFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "sort-by"},
+	Parameters: []ast.Attreibute{
+		param("list", "list"),
+		param("f", "function"),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		list := getList(env, "list")
+		f := getFunction(env, "f")
+
+		keys := make([]ast.Value, len(list))
+		for i, item := range list {
+			key, err := callFunctionValue(f, []ast.Value{item}, env.CallSite)
+			if err != nil {
+				return ast.NilValue{}, err
+			}
+			keys[i] = key
+		}
+
+		// Compare every key with the first so that sorting can't fail
+		for _, key := range keys {
+			_, err := compareValues(keys[0], key)
+			if err != nil {
+				return ast.NilValue{}, fmt.Errorf("sort-by keys must all be numbers or all be strings: %s", err)
+			}
+		}
+
+		order := make([]int, len(list))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			c, _ := compareValues(keys[order[a]], keys[order[b]])
+			return c < 0
+		})
+
+		values := make([]ast.Value, len(list))
+		for i, j := range order {
+			values[i] = list[j]
+		}
+		return ast.List{Values: values}, nil
+	},
+})
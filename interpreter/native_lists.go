@@ -0,0 +1,84 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/shreyassanthu77/cisp/ast"
+)
+
+// getList reads a parameter that was declared with the list type.
+func getList(env *Environment, name string) []ast.Value {
+	val, _ := env.getVar(name)
+	return val.(ast.List).Values
+}
+
+func getInt(env *Environment, name string) int64 {
+	val, _ := env.getVar(name)
+	return val.(ast.Int).Value
+}
+
+// nth returns the nth item of a list, indices start at 1 and negative ones
+// count from the end like in sass.
+var nthFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "nth"},
+	Parameters: []ast.Attreibute{
+		param("list", "list"),
+		param("n", "int"),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		list := getList(env, "list")
+		n := getInt(env, "n")
+
+		i := n - 1
+		if n < 0 {
+			i = int64(len(list)) + n
+		}
+
+		if n == 0 || i < 0 || i >= int64(len(list)) {
+			return ast.NilValue{}, fmt.Errorf("index %d is out of bounds for a list of length %d", n, len(list))
+		}
+		return list[i], nil
+	},
+})
+
+var lengthFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "length"},
+	Parameters: []ast.Attreibute{
+		param("list", "list"),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		return ast.Int{Value: int64(len(getList(env, "list")))}, nil
+	},
+})
+
+var appendFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "append"},
+	Parameters: []ast.Attreibute{
+		param("list", "list"),
+		param("value", ""),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		list := getList(env, "list")
+		val, _ := env.getVar("value")
+
+		values := make([]ast.Value, len(list), len(list)+1)
+		copy(values, list)
+		return ast.List{Values: append(values, val)}, nil
+	},
+})
+
+var concatFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "concat"},
+	Parameters: []ast.Attreibute{
+		param("a", "list"),
+		param("b", "list"),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		a := getList(env, "a")
+		b := getList(env, "b")
+
+		values := make([]ast.Value, 0, len(a)+len(b))
+		values = append(values, a...)
+		return ast.List{Values: append(values, b...)}, nil
+	},
+})
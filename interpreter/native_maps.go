@@ -0,0 +1,108 @@
+package interpreter
+
+import (
+	"github.com/shreyassanthu77/cisp/ast"
+)
+
+// mapIndex returns the position of key in entries.
+func mapIndex(entries []ast.MapEntry, key ast.Value) (int, bool) {
+	for i, entry := range entries {
+		if valuesEqual(entry.Key, key) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// getMap reads a parameter that was declared with the map type.
+func getMap(env *Environment, name string) []ast.MapEntry {
+	val, _ := env.getVar(name)
+	return val.(ast.Map).Entries
+}
+
+var mapGetFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "map-get"},
+	Parameters: []ast.Attreibute{
+		param("map", "map"),
+		param("key", ""),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		entries := getMap(env, "map")
+		key, _ := env.getVar("key")
+
+		i, ok := mapIndex(entries, key)
+		if !ok {
+			return ast.NilValue{}, nil
+		}
+		return entries[i].Value, nil
+	},
+})
+
+// map-merge returns a new map with the entries of b added to a, b wins
+// when both have the same key.
+var mapMergeFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "map-merge"},
+	Parameters: []ast.Attreibute{
+		param("a", "map"),
+		param("b", "map"),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		a := getMap(env, "a")
+		b := getMap(env, "b")
+
+		entries := make([]ast.MapEntry, len(a), len(a)+len(b))
+		copy(entries, a)
+		for _, entry := range b {
+			i, ok := mapIndex(entries, entry.Key)
+			if ok {
+				entries[i].Value = entry.Value
+			} else {
+				entries = append(entries, entry)
+			}
+		}
+		return ast.Map{Entries: entries}, nil
+	},
+})
+
+var mapKeysFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "map-keys"},
+	Parameters: []ast.Attreibute{
+		param("map", "map"),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		entries := getMap(env, "map")
+		keys := make([]ast.Value, len(entries))
+		for i, entry := range entries {
+			keys[i] = entry.Key
+		}
+		return ast.List{Values: keys}, nil
+	},
+})
+
+var mapValuesFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "map-values"},
+	Parameters: []ast.Attreibute{
+		param("map", "map"),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		entries := getMap(env, "map")
+		values := make([]ast.Value, len(entries))
+		for i, entry := range entries {
+			values[i] = entry.Value
+		}
+		return ast.List{Values: values}, nil
+	},
+})
+
+var mapHasKeyFn = ruleFromNativeFnCall(NativeFnCall{
+	Fn: ast.Identifier{Name: "map-has-key"},
+	Parameters: []ast.Attreibute{
+		param("map", "map"),
+		param("key", ""),
+	},
+	Handler: func(env *Environment) (ast.Value, error) {
+		key, _ := env.getVar("key")
+		_, ok := mapIndex(getMap(env, "map"), key)
+		return ast.Boolean{Value: ok}, nil
+	},
+})
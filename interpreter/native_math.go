@@ -0,0 +1,328 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/shreyassanthu77/cisp/ast"
+)
+
+// randSource backs math.random, math.seed makes it deterministic.
+var randSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// mathConstants are declared in the builtin scope and read as `$math.pi`.
+var mathConstants = map[string]ast.Value{
+	"math.pi": ast.Float{Value: math.Pi},
+	"math.e":  ast.Float{Value: math.E},
+}
+
+// mathFn defines a native function of the math namespace, it is called as
+// `math.name(...)` like a rule declared with `math > name`.
+func mathFn(n NativeFnCall) ast.Rule {
+	rule := ruleFromNativeFnCall(n)
+	rule.Selector.Path = []ast.SelectorSegment{
+		{
+			Name:       ast.Identifier{Name: "math"},
+			Combinator: ast.COMBINATOR_DESCENDANT,
+		},
+	}
+	return rule
+}
+
+// getNumber reads a parameter that was declared with the number type.
+func getNumber(env *Environment, name string) ast.Value {
+	val, _ := env.getVar(name)
+	return val
+}
+
+func isInt(v ast.Value) bool {
+	_, ok := v.(ast.Int)
+	return ok
+}
+
+// floatFn defines a math function that always returns a float.
+func floatFn(name string, f func(float64) float64) ast.Rule {
+	return mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: name},
+		Parameters: []ast.Attreibute{
+			param("x", "number"),
+		},
+		Handler: func(env *Environment) (ast.Value, error) {
+			return ast.Float{Value: f(toFloat(getNumber(env, "x")))}, nil
+		},
+	})
+}
+
+// roundingFn defines a math function that rounds a number to an int.
+func roundingFn(name string, f func(float64) float64) ast.Rule {
+	return mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: name},
+		Parameters: []ast.Attreibute{
+			param("x", "number"),
+		},
+		Handler: func(env *Environment) (ast.Value, error) {
+			x := getNumber(env, "x")
+			if isInt(x) {
+				return x, nil
+			}
+
+			rounded := f(toFloat(x))
+			if math.IsNaN(rounded) || rounded < math.MinInt64 || rounded >= math.MaxInt64 {
+				return ast.NilValue{}, fmt.Errorf("cannot round %v to an int", rounded)
+			}
+			return ast.Int{Value: int64(rounded)}, nil
+		},
+	})
+}
+
+// extremum returns the smallest number of values when sign is -1 and the
+// largest when it is 1. The result is an int only if every number is.
+func extremum(values []ast.Value, sign int) (ast.Value, error) {
+	// `math.min($list)` works like `math.min(1, 2, 3)`
+	if len(values) == 1 {
+		if list, ok := values[0].(ast.List); ok {
+			values = list.Values
+		}
+	}
+
+	if len(values) == 0 {
+		return ast.NilValue{}, fmt.Errorf("expected at least one number")
+	}
+
+	allInts := true
+	best := values[0]
+	for _, val := range values {
+		t := getValueType(val)
+		if t != val_type_int && t != val_type_float {
+			return ast.NilValue{}, fmt.Errorf("expected a number but got %s", t)
+		}
+		allInts = allInts && t == val_type_int
+
+		c, _ := compareValues(val, best)
+		if c*sign > 0 {
+			best = val
+		}
+	}
+
+	if !allInts {
+		return ast.Float{Value: toFloat(best)}, nil
+	}
+	return best, nil
+}
+
+var mathFns = []ast.Rule{
+	mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: "abs"},
+		Parameters: []ast.Attreibute{
+			param("x", "number"),
+		},
+		Handler: func(env *Environment) (ast.Value, error) {
+			x := getNumber(env, "x")
+			if i, ok := x.(ast.Int); ok {
+				if i.Value < 0 {
+					return ast.Int{Value: -i.Value}, nil
+				}
+				return i, nil
+			}
+			return ast.Float{Value: math.Abs(toFloat(x))}, nil
+		},
+	}),
+	mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: "min"},
+		Parameters: []ast.Attreibute{
+			param("numbers", "list"),
+		},
+		Variadic: true,
+		Handler: func(env *Environment) (ast.Value, error) {
+			return extremum(getList(env, "numbers"), -1)
+		},
+	}),
+	mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: "max"},
+		Parameters: []ast.Attreibute{
+			param("numbers", "list"),
+		},
+		Variadic: true,
+		Handler: func(env *Environment) (ast.Value, error) {
+			return extremum(getList(env, "numbers"), 1)
+		},
+	}),
+	// clamp takes its arguments in the same order as the css function
+	mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: "clamp"},
+		Parameters: []ast.Attreibute{
+			param("min", "number"),
+			param("value", "number"),
+			param("max", "number"),
+		},
+		Handler: func(env *Environment) (ast.Value, error) {
+			low := getNumber(env, "min")
+			high := getNumber(env, "max")
+			val, err := extremum([]ast.Value{getNumber(env, "value"), high}, -1)
+			if err != nil {
+				return ast.NilValue{}, err
+			}
+			return extremum([]ast.Value{low, val}, 1)
+		},
+	}),
+	roundingFn("floor", math.Floor),
+	roundingFn("ceil", math.Ceil),
+	// round rounds halves up like css, `math.round(-2.5)` is -2
+	roundingFn("round", func(x float64) float64 {
+		// floor(x + 0.5) without the rounding error of the addition
+		r := math.Floor(x)
+		if x-r >= 0.5 {
+			r++
+		}
+		return r
+	}),
+	mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: "sqrt"},
+		Parameters: []ast.Attreibute{
+			param("x", "number"),
+		},
+		Handler: func(env *Environment) (ast.Value, error) {
+			x := toFloat(getNumber(env, "x"))
+			if x < 0 {
+				return ast.NilValue{}, fmt.Errorf("cannot take the square root of a negative number")
+			}
+			return ast.Float{Value: math.Sqrt(x)}, nil
+		},
+	}),
+	// pow of two ints is an int as long as the exponent isn't negative and
+	// the result fits, it is a float otherwise
+	mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: "pow"},
+		Parameters: []ast.Attreibute{
+			param("base", "number"),
+			param("exponent", "number"),
+		},
+		Handler: func(env *Environment) (ast.Value, error) {
+			base := getNumber(env, "base")
+			exp := getNumber(env, "exponent")
+			if isInt(base) && isInt(exp) && exp.(ast.Int).Value >= 0 {
+				if n, ok := intPow(base.(ast.Int).Value, exp.(ast.Int).Value); ok {
+					return ast.Int{Value: n}, nil
+				}
+			}
+			return ast.Float{Value: math.Pow(toFloat(base), toFloat(exp))}, nil
+		},
+	}),
+	mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: "log"},
+		Parameters: []ast.Attreibute{
+			param("x", "number"),
+		},
+		Handler: func(env *Environment) (ast.Value, error) {
+			return logarithm(toFloat(getNumber(env, "x")), math.E)
+		},
+	}),
+	mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: "log"},
+		Parameters: []ast.Attreibute{
+			param("x", "number"),
+			param("base", "number"),
+		},
+		Handler: func(env *Environment) (ast.Value, error) {
+			return logarithm(toFloat(getNumber(env, "x")), toFloat(getNumber(env, "base")))
+		},
+	}),
+	floatFn("sin", math.Sin),
+	floatFn("cos", math.Cos),
+	floatFn("tan", math.Tan),
+	floatFn("asin", math.Asin),
+	floatFn("acos", math.Acos),
+	floatFn("atan", math.Atan),
+	mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: "atan2"},
+		Parameters: []ast.Attreibute{
+			param("y", "number"),
+			param("x", "number"),
+		},
+		Handler: func(env *Environment) (ast.Value, error) {
+			y := toFloat(getNumber(env, "y"))
+			x := toFloat(getNumber(env, "x"))
+			return ast.Float{Value: math.Atan2(y, x)}, nil
+		},
+	}),
+	// random returns a float between 0 and 1, like sass
+	mathFn(NativeFnCall{
+		Fn:         ast.Identifier{Name: "random"},
+		Parameters: []ast.Attreibute{},
+		Handler: func(env *Environment) (ast.Value, error) {
+			return ast.Float{Value: randSource.Float64()}, nil
+		},
+	}),
+	// random(n) returns an int between 1 and n
+	mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: "random"},
+		Parameters: []ast.Attreibute{
+			param("limit", "int"),
+		},
+		Handler: func(env *Environment) (ast.Value, error) {
+			limit := getInt(env, "limit")
+			if limit < 1 {
+				return ast.NilValue{}, fmt.Errorf("the limit of random must be at least 1 but got %d", limit)
+			}
+			return ast.Int{Value: randSource.Int63n(limit) + 1}, nil
+		},
+	}),
+	mathFn(NativeFnCall{
+		Fn: ast.Identifier{Name: "seed"},
+		Parameters: []ast.Attreibute{
+			param("seed", "int"),
+		},
+		Handler: func(env *Environment) (ast.Value, error) {
+			randSource.Seed(getInt(env, "seed"))
+			return ast.NilValue{}, nil
+		},
+	}),
+}
+
+// intPow raises base to a non-negative exponent, it reports false when the
+// result doesn't fit in an int.
+func intPow(base, exp int64) (int64, bool) {
+	result := int64(1)
+	ok := true
+	for exp > 0 {
+		if exp&1 == 1 {
+			if result, ok = mulInt(result, base); !ok {
+				return 0, false
+			}
+		}
+		exp >>= 1
+		if exp > 0 {
+			if base, ok = mulInt(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
+
+// mulInt multiplies two ints, it reports false when the product overflows.
+func mulInt(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	c := a * b
+	if c/b != a {
+		return 0, false
+	}
+	return c, true
+}
+
+func logarithm(x, base float64) (ast.Value, error) {
+	if x <= 0 {
+		return ast.NilValue{}, fmt.Errorf("cannot take the logarithm of %v", x)
+	}
+	if base <= 0 || base == 1 {
+		return ast.NilValue{}, fmt.Errorf("%v is not a valid base for a logarithm", base)
+	}
+	return ast.Float{Value: math.Log(x) / math.Log(base)}, nil
+}
@@ -0,0 +1,214 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shreyassanthu77/cisp/ast"
+)
+
+// Strings are indexed by character, not byte, starting at 1 and counting
+// from the end for negative indexes, like sass.
+
+// maxStringLength is the size in bytes of the longest string repeat builds.
+const maxStringLength = 1 << 26
+
+func getString(env *Environment, name string) string {
+	val, _ := env.getVar(name)
+	return val.(ast.String).Value
+}
+
+// runeIndex turns a 1-based, possibly negative, index into a string of
+// length characters into a 0-based one. It reports false when the index is
+// out of bounds.
+func runeIndex(n int64, length int) (int, bool) {
+	i := n - 1
+	if n < 0 {
+		i = int64(length) + n
+	}
+	if n == 0 || i < 0 || i >= int64(length) {
+		return 0, false
+	}
+	return int(i), true
+}
+
+// clampIndex is runeIndex for slicing, an index past either end of the
+// string is moved back to it.
+func clampIndex(n int64, length int) int {
+	i := n - 1
+	if n < 0 {
+		i = int64(length) + n
+	}
+	return int(max(min(i, int64(length)), -1))
+}
+
+func stringFn(name string, params []ast.Attreibute, handler func(env *Environment) (ast.Value, error)) ast.Rule {
+	return ruleFromNativeFnCall(NativeFnCall{
+		Fn:         ast.Identifier{Name: name},
+		Parameters: params,
+		Handler:    handler,
+	})
+}
+
+// slice returns the characters of s from start through end, both included.
+func slice(s []rune, start, end int64) string {
+	from := max(clampIndex(start, len(s)), 0)
+	to := clampIndex(end, len(s))
+	if to >= len(s) {
+		to = len(s) - 1
+	}
+	if from > to {
+		return ""
+	}
+	return string(s[from : to+1])
+}
+
+var stringFns = []ast.Rule{
+	stringFn("str-length", []ast.Attreibute{
+		param("string", "string"),
+	}, func(env *Environment) (ast.Value, error) {
+		return ast.Int{Value: int64(len([]rune(getString(env, "string"))))}, nil
+	}),
+	stringFn("str-slice", []ast.Attreibute{
+		param("string", "string"),
+		param("start", "int"),
+	}, func(env *Environment) (ast.Value, error) {
+		s := []rune(getString(env, "string"))
+		return ast.String{Value: slice(s, getInt(env, "start"), -1)}, nil
+	}),
+	stringFn("str-slice", []ast.Attreibute{
+		param("string", "string"),
+		param("start", "int"),
+		param("end", "int"),
+	}, func(env *Environment) (ast.Value, error) {
+		s := []rune(getString(env, "string"))
+		return ast.String{Value: slice(s, getInt(env, "start"), getInt(env, "end"))}, nil
+	}),
+	// str-index returns 0 when the substring isn't found
+	stringFn("str-index", []ast.Attreibute{
+		param("string", "string"),
+		param("substring", "string"),
+	}, func(env *Environment) (ast.Value, error) {
+		s := getString(env, "string")
+		i := strings.Index(s, getString(env, "substring"))
+		if i < 0 {
+			return ast.Int{Value: 0}, nil
+		}
+		return ast.Int{Value: int64(len([]rune(s[:i]))) + 1}, nil
+	}),
+	stringFn("str-insert", []ast.Attreibute{
+		param("string", "string"),
+		param("insert", "string"),
+		param("index", "int"),
+	}, func(env *Environment) (ast.Value, error) {
+		s := []rune(getString(env, "string"))
+		n := getInt(env, "index")
+
+		// Like sass, -1 inserts at the very end
+		i := clampIndex(n, len(s))
+		if n < 0 {
+			i++
+		}
+		i = min(max(i, 0), len(s))
+		return ast.String{Value: string(s[:i]) + getString(env, "insert") + string(s[i:])}, nil
+	}),
+	stringFn("to-upper-case", []ast.Attreibute{
+		param("string", "string"),
+	}, func(env *Environment) (ast.Value, error) {
+		return ast.String{Value: strings.ToUpper(getString(env, "string"))}, nil
+	}),
+	stringFn("to-lower-case", []ast.Attreibute{
+		param("string", "string"),
+	}, func(env *Environment) (ast.Value, error) {
+		return ast.String{Value: strings.ToLower(getString(env, "string"))}, nil
+	}),
+	// split with an empty separator splits a string into its characters
+	stringFn("split", []ast.Attreibute{
+		param("string", "string"),
+		param("separator", "string"),
+	}, func(env *Environment) (ast.Value, error) {
+		parts := strings.Split(getString(env, "string"), getString(env, "separator"))
+		values := make([]ast.Value, len(parts))
+		for i, part := range parts {
+			values[i] = ast.String{Value: part}
+		}
+		return ast.List{Values: values}, nil
+	}),
+	stringFn("join", []ast.Attreibute{
+		param("list", "list"),
+	}, func(env *Environment) (ast.Value, error) {
+		return joinList(getList(env, "list"), ""), nil
+	}),
+	stringFn("join", []ast.Attreibute{
+		param("list", "list"),
+		param("separator", "string"),
+	}, func(env *Environment) (ast.Value, error) {
+		return joinList(getList(env, "list"), getString(env, "separator")), nil
+	}),
+	stringFn("trim", []ast.Attreibute{
+		param("string", "string"),
+	}, func(env *Environment) (ast.Value, error) {
+		return ast.String{Value: strings.TrimSpace(getString(env, "string"))}, nil
+	}),
+	stringFn("replace", []ast.Attreibute{
+		param("string", "string"),
+		param("old", "string"),
+		param("new", "string"),
+	}, func(env *Environment) (ast.Value, error) {
+		s := strings.ReplaceAll(getString(env, "string"), getString(env, "old"), getString(env, "new"))
+		return ast.String{Value: s}, nil
+	}),
+	stringFn("starts-with", []ast.Attreibute{
+		param("string", "string"),
+		param("prefix", "string"),
+	}, func(env *Environment) (ast.Value, error) {
+		return ast.Boolean{Value: strings.HasPrefix(getString(env, "string"), getString(env, "prefix"))}, nil
+	}),
+	stringFn("ends-with", []ast.Attreibute{
+		param("string", "string"),
+		param("suffix", "string"),
+	}, func(env *Environment) (ast.Value, error) {
+		return ast.Boolean{Value: strings.HasSuffix(getString(env, "string"), getString(env, "suffix"))}, nil
+	}),
+	stringFn("contains", []ast.Attreibute{
+		param("string", "string"),
+		param("substring", "string"),
+	}, func(env *Environment) (ast.Value, error) {
+		return ast.Boolean{Value: strings.Contains(getString(env, "string"), getString(env, "substring"))}, nil
+	}),
+	stringFn("repeat", []ast.Attreibute{
+		param("string", "string"),
+		param("count", "int"),
+	}, func(env *Environment) (ast.Value, error) {
+		str := getString(env, "string")
+		count := getInt(env, "count")
+		if count < 0 {
+			return ast.NilValue{}, fmt.Errorf("cannot repeat a string %d times", count)
+		}
+		if count > 0 && int64(len(str)) > maxStringLength/count {
+			return ast.NilValue{}, fmt.Errorf("cannot repeat a string %d times, the result would be longer than %d bytes", count, maxStringLength)
+		}
+		return ast.String{Value: strings.Repeat(str, int(count))}, nil
+	}),
+	stringFn("char-at", []ast.Attreibute{
+		param("string", "string"),
+		param("index", "int"),
+	}, func(env *Environment) (ast.Value, error) {
+		s := []rune(getString(env, "string"))
+		n := getInt(env, "index")
+		i, ok := runeIndex(n, len(s))
+		if !ok {
+			return ast.NilValue{}, fmt.Errorf("index %d is out of bounds for a string of length %d", n, len(s))
+		}
+		return ast.String{Value: string(s[i])}, nil
+	}),
+}
+
+// joinList concatenates the items of a list the way print shows them.
+func joinList(list []ast.Value, separator string) ast.Value {
+	parts := make([]string, len(list))
+	for i, item := range list {
+		parts[i] = stringify(item)
+	}
+	return ast.String{Value: strings.Join(parts, separator)}
+}
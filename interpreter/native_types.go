@@ -0,0 +1,117 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/shreyassanthu77/cisp/ast"
+)
+
+func conversionError(val ast.Value, typ string) error {
+	return fmt.Errorf("cannot convert %s %s to %s", getValueType(val), stringifyNested(val), typ)
+}
+
+func toInt(val ast.Value) (ast.Value, error) {
+	switch val := val.(type) {
+	case ast.Int:
+		return val, nil
+	case ast.Float:
+		// Floats are truncated towards zero
+		f := math.Trunc(val.Value)
+		if math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return ast.NilValue{}, conversionError(val, "an int")
+		}
+		return ast.Int{Value: int64(f)}, nil
+	case ast.String:
+		i, err := strconv.ParseInt(strings.TrimSpace(val.Value), 10, 64)
+		if err != nil {
+			return ast.NilValue{}, conversionError(val, "an int")
+		}
+		return ast.Int{Value: i}, nil
+	}
+	return ast.NilValue{}, conversionError(val, "an int")
+}
+
+func toFloatValue(val ast.Value) (ast.Value, error) {
+	switch val := val.(type) {
+	case ast.Int:
+		return ast.Float{Value: float64(val.Value)}, nil
+	case ast.Float:
+		return val, nil
+	case ast.String:
+		f, err := strconv.ParseFloat(strings.TrimSpace(val.Value), 64)
+		if err != nil {
+			return ast.NilValue{}, conversionError(val, "a float")
+		}
+		return ast.Float{Value: f}, nil
+	}
+	return ast.NilValue{}, conversionError(val, "a float")
+}
+
+// toBool only converts booleans and the strings "true" and "false", CRAP has
+// no notion of truthiness.
+func toBool(val ast.Value) (ast.Value, error) {
+	switch val := val.(type) {
+	case ast.Boolean:
+		return val, nil
+	case ast.String:
+		switch strings.TrimSpace(val.Value) {
+		case "true":
+			return ast.Boolean{Value: true}, nil
+		case "false":
+			return ast.Boolean{Value: false}, nil
+		}
+	}
+	return ast.NilValue{}, conversionError(val, "a bool")
+}
+
+// valueFn defines a native function of one value of any type, `()`
+// included.
+func valueFn(name string, convert func(ast.Value) (ast.Value, error)) ast.Rule {
+	return ruleFromNativeFnCall(NativeFnCall{
+		Fn: ast.Identifier{Name: name},
+		Parameters: []ast.Attreibute{
+			{
+				Name:    ast.Identifier{Name: "value"},
+				Default: ast.NilValue{},
+			},
+		},
+		Handler: func(env *Environment) (ast.Value, error) {
+			val, _ := env.getVar("value")
+			return convert(val)
+		},
+	})
+}
+
+// predicateFn defines `is-<typ>`, which reports whether its argument matches
+// the type annotation typ.
+func predicateFn(typ string) ast.Rule {
+	return valueFn("is-"+typ, func(val ast.Value) (ast.Value, error) {
+		return ast.Boolean{Value: isOfType(typ, val)}, nil
+	})
+}
+
+var typeFns = []ast.Rule{
+	valueFn("type-of", func(val ast.Value) (ast.Value, error) {
+		return ast.String{Value: getValueType(val).String()}, nil
+	}),
+	valueFn("to-string", func(val ast.Value) (ast.Value, error) {
+		return ast.String{Value: stringify(val)}, nil
+	}),
+	valueFn("to-int", toInt),
+	valueFn("to-float", toFloatValue),
+	valueFn("to-bool", toBool),
+	predicateFn("int"),
+	predicateFn("float"),
+	predicateFn("number"),
+	predicateFn("string"),
+	predicateFn("bool"),
+	predicateFn("nil"),
+	predicateFn("list"),
+	predicateFn("map"),
+	predicateFn("record"),
+	predicateFn("enum"),
+	predicateFn("function"),
+}
@@ -0,0 +1,35 @@
+package interpreter
+
+import "testing"
+
+func TestOperators(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"modulo", `main { @return 7 % 3; }`, "1"},
+		{"modulo takes the sign of the divisor", `main { @return (-7 % 3, 7 % -3); }`, "[2, -2]"},
+		{"float modulo", `main { @return 7.5 % 2; }`, "1.5"},
+		{"power", `main { @return 2 ^ 10; }`, "1024"},
+		{"power is right associative", `main { @return 2 ^ 3 ^ 2; }`, "512"},
+		{"power binds tighter than minus", `main { @return -2 ^ 2; }`, "-4"},
+		{"negative exponent", `main { @return 2 ^ -1; }`, "0.5"},
+		{"overflowing power is a float", `main { @return 2 ^ 64; }`, "1.8446744073709552e+19"},
+		{"largest int power", `main { @return (-2) ^ 63; }`, "-9223372036854775808"},
+		{"bitwise not", `main { @return ~5; }`, "-6"},
+		{"bitwise and", `main { @return 6 & 3; }`, "2"},
+		{"bitwise and without spaces", `main { @return 6&3; }`, "2"},
+		{"bitwise and with a space before", `main { @return 6 &3; }`, "2"},
+		{"bitwise or", `main { @return 6 | 3; }`, "7"},
+		{"shifts", `main { @return (1 << 4, 256 >> 2, 1 << 63); }`, "[16, 64, -9223372036854775808]"},
+		{"bitwise operators bind tighter than comparisons", `main { @return 5 & 1 == 1; }`, "true"},
+		{"anonymous rule after &", `main { @return map((1, 2), &[v] { @return $v * 2; }); }`, "[2, 4]"},
+	})
+}
+
+func TestOperatorErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"modulo by zero", "main {\n  @return 1 % 0;\n}", "modulo by zero", 2},
+		{"shift too far", "main {\n  @return 1 << 64;\n}", "cannot shift by 64", 2},
+		{"negative shift", "main {\n  @return 1 >> -1;\n}", "cannot shift by -1", 2},
+		{"bitwise and of floats", "main {\n  @return 1.5 & 1;\n}", "invalid types for &", 2},
+		{"bitwise not of a float", "main {\n  @return ~1.5;\n}", "invalid type for unary operator ~", 2},
+	})
+}
@@ -0,0 +1,42 @@
+package interpreter
+
+import (
+	"github.com/shreyassanthu77/cisp/ast"
+)
+
+func isKnownPseudoClass(name string) bool {
+	switch name {
+	case "where", "not":
+		return true
+	}
+	return false
+}
+
+func evalPseudoClass(pseudo ast.PseudoClass, env *Environment) (bool, error) {
+	val, err := evalValue(pseudo.Argument, env)
+	if err != nil {
+		return false, err
+	}
+
+	cond, ok := val.(ast.Boolean)
+	if !ok {
+		return false, runtimeError(pseudo.Argument.GetSpan(), ":%s guard must evaluate to a boolean", pseudo.Name.Name)
+	}
+
+	if pseudo.Name.Name == "not" {
+		return !cond.Value, nil
+	}
+	return cond.Value, nil
+}
+
+// guardsHold reports whether every pseudo-class of selector accepts the
+// parameters already bound in env.
+func guardsHold(selector ast.Selector, env *Environment) (bool, error) {
+	for _, pseudo := range selector.PseudoClasses {
+		ok, err := evalPseudoClass(pseudo, env)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
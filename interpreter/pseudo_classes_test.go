@@ -0,0 +1,27 @@
+package interpreter
+
+import "testing"
+
+const fibRules = `
+fib[n]:where($n < 2) { @return $n; }
+fib[n] { @return fib($n - 1) + fib($n - 2); }
+`
+
+func TestGuards(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"where", `f[x]:where($x > 0) { @return "pos"; } f[x] { @return "other"; } main { @return (f(1), f(-1)); }`, `["pos", "other"]`},
+		{"not", `f[x]:not($x > 0) { @return "nonpos"; } f[x] { @return "pos"; } main { @return (f(1), f(0)); }`, `["pos", "nonpos"]`},
+		{"recursion on clauses", fibRules + `main { @return fib(10); }`, "55"},
+		{"every guard has to hold", `f[x]:where($x > 0):not($x > 10) { @return "small"; } f[x] { @return "other"; } main { @return (f(5), f(20), f(-1)); }`, `["small", "other", "other"]`},
+		{"guards see every parameter", `f[a][b]:where($a == $b) { @return "same"; } f[a][b] { @return "different"; } main { @return (f(1, 1), f(1, 2)); }`, `["same", "different"]`},
+		{"guards call rules", `pos[x] { @return $x > 0; } f[x]:where(pos($x)) { @return 1; } f[x] { @return 0; } main { @return (f(3), f(-3)); }`, "[1, 0]"},
+	})
+}
+
+func TestGuardErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"no clause matches", "f[x]:where($x > 0) { @return 1; }\nmain {\n  @return f(-1);\n}", "no clause of f matches the given arguments", 3},
+		{"guards are booleans", "f[x]:where($x) { @return 1; }\nmain {\n  @return f(1);\n}", ":where guard must evaluate to a boolean", 1},
+		{"unknown pseudo-class", "f[x]:nope($x) { @return 1; }\nmain {\n  @return f(1);\n}", "unknown pseudo-class :nope", 1},
+	})
+}
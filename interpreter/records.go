@@ -0,0 +1,138 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/lexer"
+)
+
+// RecordValue is an instance of a type declared with `@type point[x][y];`,
+// Values holds one value per attribute of the type's selector.
+type RecordValue struct {
+	Type   ast.Selector
+	Values []ast.Value
+	Span   lexer.Span
+}
+
+func (r RecordValue) IsValue() {}
+
+func (r RecordValue) GetSpan() lexer.Span {
+	return r.Span
+}
+
+func (r RecordValue) Name() string {
+	return r.Type.Identifier.Name
+}
+
+func (r RecordValue) field(name string) (ast.Value, error) {
+	for i, attr := range r.Type.Atrributes {
+		if attr.Name.Name == name {
+			return r.Values[i], nil
+		}
+	}
+	return ast.NilValue{}, fmt.Errorf("%s has no field %s", r.Name(), name)
+}
+
+func (r RecordValue) String() string {
+	fields := make([]string, len(r.Values))
+	for i, attr := range r.Type.Atrributes {
+		fields[i] = attr.Name.Name + ": " + stringifyNested(r.Values[i])
+	}
+	return fmt.Sprintf("%s(%s)", r.Name(), strings.Join(fields, ", "))
+}
+
+// evalTypeRule defines the constructor of a record type. The constructor is
+// an ordinary rule so defaults and typed attributes work like they do for
+// any other function.
+func evalTypeRule(env *Environment, rule ast.AtRule) (ast.Value, error) {
+	if rule.Selector == nil {
+		return ast.NilValue{}, fmt.Errorf("type rules should look like @type point[x][y]")
+	}
+
+	selector := *rule.Selector
+	if len(selector.Path) > 0 || len(selector.PseudoClasses) > 0 {
+		return ast.NilValue{}, fmt.Errorf("a type can only be declared with a name and attributes")
+	}
+
+	seen := map[string]bool{}
+	for _, attr := range selector.Atrributes {
+		if seen[attr.Name.Name] {
+			return ast.NilValue{}, runtimeError(attr.Span, "field %s is declared twice", attr.Name.Name)
+		}
+		seen[attr.Name.Name] = true
+	}
+
+	constructor := ruleFromNativeFnCall(NativeFnCall{
+		Fn:         selector.Identifier,
+		Parameters: selector.Atrributes,
+		Handler: func(env *Environment) (ast.Value, error) {
+			values := make([]ast.Value, len(selector.Atrributes))
+			for i, attr := range selector.Atrributes {
+				values[i], _ = env.getVar(attr.Name.Name)
+			}
+			return RecordValue{Type: selector, Values: values}, nil
+		},
+	})
+	constructor.Selector.Span = selector.Span
+	constructor.Span = rule.Span
+
+	return ast.NilValue{}, env.setFn(constructor)
+}
+
+// evalFieldAccess resolves `$p.x.y` when no variable has that exact name.
+func evalFieldAccess(name string, env *Environment) (ast.Value, error) {
+	parts := strings.Split(name, ".")
+	val, err := env.getVar(parts[0])
+	fields := parts[1:]
+	if err != nil {
+		// `$m.pi` reads the variable pi of the module used as m
+		val, err = env.getModuleVar(parts[0], parts[1])
+		fields = parts[2:]
+	}
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	for _, field := range fields {
+		val, err = getField(val, field)
+		if err != nil {
+			return ast.NilValue{}, err
+		}
+	}
+	return val, nil
+}
+
+func getField(val ast.Value, field string) (ast.Value, error) {
+	record, ok := val.(RecordValue)
+	if !ok {
+		return ast.NilValue{}, fmt.Errorf("cannot access field %s of a %s", field, getValueType(val))
+	}
+	return record.field(field)
+}
+
+// evalAttrCall evaluates `attr($p, x)`. The field is given as a bare name, or
+// a string, so the call can't go through normal argument evaluation.
+func evalAttrCall(call ast.FunctionCall, env *Environment) (ast.Value, error) {
+	if len(call.Parameters) != 2 {
+		return ast.NilValue{}, fmt.Errorf("expected 2 parameters, got %d", len(call.Parameters))
+	}
+
+	record, err := evalValue(call.Parameters[0], env)
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	var field string
+	switch name := call.Parameters[1].(type) {
+	case ast.Identifier:
+		field = name.Name
+	case ast.String:
+		field = name.Value
+	default:
+		return ast.NilValue{}, runtimeError(name.GetSpan(), "attr expects a field name")
+	}
+
+	return getField(record, field)
+}
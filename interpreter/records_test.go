@@ -0,0 +1,29 @@
+package interpreter
+
+import "testing"
+
+const pointType = `@type point[x:number][y=0];`
+
+func TestRecords(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"construct", pointType + `main { @return point(1, 2); }`, "point(x: 1, y: 2)"},
+		{"defaults", pointType + `main { @return point(1, ()); }`, "point(x: 1, y: 0)"},
+		{"fields", pointType + `main { --p: point(1, 2); @return ($p.x, $p.y); }`, "[1, 2]"},
+		{"attr", pointType + `main { --p: point(1, 2); @return (attr($p, x), attr($p, "y")); }`, "[1, 2]"},
+		{"nested fields", pointType + `@type line[from][to]; main { --l: line(point(1, 2), point(3, 4)); @return $l.to.x; }`, "3"},
+		{"equality", pointType + `main { @return (point(1, 2) == point(1, 2), point(1, 2) == point(2, 1)); }`, "[true, false]"},
+		{"types with the same fields differ", pointType + `@type vec[x][y]; main { @return point(1, 2) == vec(1, 2); }`, "false"},
+		{"type-of", pointType + `main { @return (type-of(point(1, 2)), is-record(point(1, 2))); }`, `["record", true]`},
+		{"records as parameters", pointType + `len[p:record] { @return $p.x + $p.y; } main { @return len(point(1, 2)); }`, "3"},
+	})
+}
+
+func TestRecordErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"missing field", pointType + "\nmain {\n  --p: point(1, 2);\n  @return $p.z;\n}", "point has no field z", 4},
+		{"field of a non record", "main {\n  --n: 1;\n  @return $n.x;\n}", "cannot access field x of a int", 3},
+		{"typed field", pointType + "\nmain {\n  @return point(\"a\", 1);\n}", "parameter x expects a number but got string", 3},
+		{"field declared twice", "@type p[x][x];\nmain { }", "field x is declared twice", 1},
+		{"no namespaces", "@type a > p[x];\nmain { }", "a type can only be declared with a name and attributes", 1},
+	})
+}
@@ -4,23 +4,152 @@ import (
 	"fmt"
 
 	"github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/lexer"
+	"github.com/shreyassanthu77/cisp/loader"
 )
 
 func Eval(program ast.Program) (ast.Value, error) {
+	return EvalModule(&loader.Module{Program: program})
+}
+
+// EvalModule runs the main rule of mod after evaluating the modules it
+// imports.
+func EvalModule(mod *loader.Module) (ast.Value, error) {
+	rootEnv, err := evalModule(mod, moduleCache{})
+	if err != nil {
+		return ast.NilValue{}, err
+	}
+
+	main, err := rootEnv.genFn("main")
+	if err != nil {
+		return ast.NilValue{}, fmt.Errorf("no main rule found")
+	}
+
+	res, err := callFn(main, []ast.Value{}, lexer.Span{})
+	if err != nil {
+		return ast.NilValue{}, pushFrame(err, "main", lexer.Span{})
+	}
+	return res, nil
+}
+
+// evalModule defines the rules of mod and evaluates its globals, returning
+// its global scope.
+func evalModule(mod *loader.Module, cache moduleCache) (*Environment, error) {
+	if env, ok := cache[mod]; ok {
+		return env, nil
+	}
+
 	rootEnv := NewRootEnv()
-	for _, rule := range program.Rules {
+
+	// Rules, types, enums and imports are defined first so globals can be
+	// initialized with calls to rules declared further down
+	globals := []ast.Statement{}
+	imports := []ast.AtRule{}
+	for _, rule := range mod.Program.Rules {
 		switch rule := rule.(type) {
 		case ast.AtRule:
-			panic("global at-rules not supported yet")
+			switch {
+			case rule.Name == "type":
+				_, err := evalTypeRule(rootEnv, rule)
+				if err != nil {
+					return nil, withSpan(err, rule.Span)
+				}
+			case rule.Name == "enum":
+				_, err := evalEnumRule(rootEnv, rule)
+				if err != nil {
+					return nil, withSpan(err, rule.Span)
+				}
+			case loader.IsImport(rule):
+				imports = append(imports, rule)
+			default:
+				globals = append(globals, rule)
+			}
 		case ast.Rule:
-			rootEnv.setFn(rule)
+			err := rootEnv.setFn(rule)
+			if err != nil {
+				return nil, withSpan(err, rule.Selector.Span)
+			}
+		case ast.Declaration:
+			globals = append(globals, rule)
 		}
 	}
 
-	main, err := rootEnv.genFn("main")
+	// Imports come after the module's own rules, which take precedence
+	for _, rule := range imports {
+		err := evalImport(rootEnv, mod, rule, cache)
+		if err != nil {
+			return nil, withSpan(err, rule.Span)
+		}
+	}
+
+	err := evalGlobals(globals, rootEnv)
 	if err != nil {
-		return ast.NilValue{}, fmt.Errorf("no main rule found")
+		return nil, err
+	}
+
+	cache[mod] = rootEnv
+	return rootEnv, nil
+}
+
+// evalGlobals evaluates the top level declarations and at-rules in the order
+// they were written.
+func evalGlobals(stmts []ast.Statement, env *Environment) error {
+	res, err := evalStatementList(stmts, env)
+	if err != nil {
+		return err
+	}
+
+	switch res := res.(type) {
+	case ReturnValue:
+		return runtimeError(res.Value.GetSpan(), "@return used outside of a rule")
+	case BreakValue:
+		return runtimeError(res.Span, "@break used outside of a loop")
+	case ContinueValue:
+		return runtimeError(res.Span, "@continue used outside of a loop")
+	}
+	return nil
+}
+
+// evalConfigRule declares the variables of a `@config` block. They are
+// constants, the program can read its configuration but not change it.
+func evalConfigRule(env *Environment, rule ast.AtRule) (ast.Value, error) {
+	if !isGlobalEnv(env) {
+		return ast.NilValue{}, fmt.Errorf("config rules can only be used at the top level")
 	}
 
-	return evalRule(main, []ast.Value{}, rootEnv)
+	if len(rule.Parameters) != 0 {
+		return ast.NilValue{}, fmt.Errorf("config rules don't take parameters")
+	}
+
+	for _, stmt := range rule.Body {
+		decl, ok := stmt.(ast.Declaration)
+		if !ok || !isVariable(decl.Property.Name) {
+			return ast.NilValue{}, runtimeError(stmt.GetSpan(), "config rules can only contain variable declarations")
+		}
+
+		decl.Important = true
+		_, err := evalStmt(decl, &IfState{}, env)
+		if err != nil {
+			return ast.NilValue{}, err
+		}
+	}
+	return ast.NilValue{}, nil
+}
+
+// evalInitRule runs an `@init` hook. Like a rule it gets its own scope and
+// can stop early with `@return`.
+func evalInitRule(env *Environment, rule ast.AtRule) (ast.Value, error) {
+	if !isGlobalEnv(env) {
+		return ast.NilValue{}, fmt.Errorf("init rules can only be used at the top level")
+	}
+
+	if len(rule.Parameters) != 0 {
+		return ast.NilValue{}, fmt.Errorf("init rules don't take parameters")
+	}
+
+	_, err := evalRule(ast.Rule{Body: rule.Body}, env.fork())
+	if err != nil {
+		return ast.NilValue{}, pushFrame(err, "@init", rule.Span)
+	}
+	return ast.NilValue{}, nil
 }
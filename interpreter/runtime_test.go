@@ -0,0 +1,28 @@
+package interpreter
+
+import "testing"
+
+func TestGlobals(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"declaration", `--g: 2; main { @return $g; }`, "2"},
+		{"globals call rules declared later", `--g: double(2); double[x] { @return $x * 2; } main { @return $g; }`, "4"},
+		{"globals in order", `--a: 1; --b: $a + 1; main { @return $b; }`, "2"},
+		{"const", `@const --pi: 3.14; main { @return $pi; }`, "3.14"},
+		{"config", `@config { --debug: true; --level: 2; } main { @return ($debug, $level); }`, "[true, 2]"},
+		{"init returns early", `@init { @return 0; @throw "unreachable"; } main { @return 1; }`, "1"},
+		{"init has a scope of its own", `--n: 0; @init { --n: 1; } main { @return $n; }`, "0"},
+	})
+}
+
+func TestGlobalErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"reassign a const", "@const --pi: 3;\nmain {\n  --pi: 4;\n}", "cannot reassign --pi", 3},
+		{"reassign config", "@config { --debug: true; }\n--debug: false;\nmain { }", "cannot reassign --debug", 2},
+		{"config only declares variables", "@config {\n  color: red;\n}\nmain { }", "config rules can only contain variable declarations", 2},
+		{"config at the top level", "main {\n  @config { --a: 1; }\n}", "config rules can only be used at the top level", 2},
+		{"init at the top level", "main {\n  @init { }\n}", "init rules can only be used at the top level", 2},
+		{"return outside of a rule", "@return 1;\nmain { }", "@return used outside of a rule", 1},
+		{"init runs before main", "@init {\n  @throw \"init\";\n}\nmain { @throw \"main\"; }", "init", 2},
+		{"errors in init", "@init {\n  --x: 1 / 0;\n}\nmain { }", "division by zero", 2},
+	})
+}
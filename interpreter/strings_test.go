@@ -0,0 +1,31 @@
+package interpreter
+
+import "testing"
+
+func TestStrings(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"str-length counts characters", `main { @return (str-length("abc"), str-length("日本語"), str-length("")); }`, "[3, 3, 0]"},
+		{"str-slice", `main { @return (str-slice("héllo", 2, 4), str-slice("héllo", 3)); }`, `["éll", "llo"]`},
+		{"str-slice from the end", `main { @return str-slice("héllo", -3, -1); }`, `"llo"`},
+		{"str-index", `main { @return (str-index("日本語", "語"), str-index("abc", "z")); }`, "[3, 0]"},
+		{"str-insert", `main { @return str-insert("héllo", "XY", 3); }`, `"héXYllo"`},
+		{"case", `main { @return (to-upper-case("éa"), to-lower-case("ÉA")); }`, `["ÉA", "éa"]`},
+		{"split and join", `main { @return (split("a,b,c", ","), join(("a", "b"), "-")); }`, `[["a", "b", "c"], "a-b"]`},
+		{"trim", `main { @return trim("  a b  "); }`, `"a b"`},
+		{"replace", `main { @return replace("a-b-c", "-", "+"); }`, `"a+b+c"`},
+		{"predicates", `main { @return (starts-with("abc", "ab"), ends-with("abc", "bc"), contains("abc", "d")); }`, "[true, true, false]"},
+		{"repeat", `main { @return repeat("ab", 3); }`, `"ababab"`},
+		{"repeat zero times", `main { @return repeat("ab", 0); }`, `""`},
+		{"char-at", `main { @return (char-at("日本語", 2), char-at("abc", -1)); }`, `["本", "c"]`},
+		{"comparison", `main { @return ("a" < "b", "b" > "a", "a" == "a"); }`, "[true, true, true]"},
+	})
+}
+
+func TestStringErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"repeat too long", "main {\n  @return repeat(\"ab\", 9223372036854775807);\n}", "cannot repeat a string", 2},
+		{"negative repeat", "main {\n  @return repeat(\"ab\", -1);\n}", "cannot repeat a string -1 times", 2},
+		{"char-at out of range", "main {\n  @return char-at(\"abc\", 4);\n}", "index 4 is out of bounds for a string of length 3", 2},
+		{"str-length of a number", "main {\n  @return str-length(1);\n}", "parameter string expects a string but got int", 2},
+	})
+}
@@ -0,0 +1,26 @@
+package interpreter
+
+import "testing"
+
+func TestConversions(t *testing.T) {
+	runValueTests(t, []valueTest{
+		{"type-of", `f { } main { @return (type-of(1), type-of(1.5), type-of("a"), type-of(true), type-of(()), type-of([]), type-of((a: 1)), type-of(fn(f))); }`, `["int", "float", "string", "bool", "nil", "list", "map", "function"]`},
+		{"predicates", `main { @return (is-int(1), is-int(1.5), is-float(1.5), is-number(1), is-string("a"), is-bool(false), is-nil(()), is-list([]), is-map((a: 1))); }`, "[true, false, true, true, true, true, true, true, true]"},
+		{"to-string", `main { @return (to-string(1), to-string(1.5), to-string(true), to-string("a"), to-string((1, "a"))); }`, `["1", "1.5", "true", "a", "[1, \"a\"]"]`},
+		{"to-int", `main { @return (to-int(" 42 "), to-int(2.9), to-int(-2.9), to-int(3)); }`, "[42, 2, -2, 3]"},
+		{"to-float", `main { @return (to-float("1.5"), to-float(2), type-of(to-float(2))); }`, `[1.5, 2, "float"]`},
+		{"to-bool", `main { @return (to-bool("true"), to-bool("false"), to-bool(true)); }`, "[true, false, true]"},
+		{"round trip", `main { @return to-int(to-string(123)) + 1; }`, "124"},
+	})
+}
+
+func TestConversionErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"to-int of text", "main {\n  @return to-int(\"abc\");\n}", `cannot convert string "abc" to an int`, 2},
+		{"to-int of a float string", "main {\n  @return to-int(\"1.5\");\n}", "cannot convert string", 2},
+		{"to-int too big", "main {\n  @return to-int(10.0 ^ 19);\n}", "cannot convert float", 2},
+		{"to-int of a list", "main {\n  @return to-int((1, 2));\n}", "cannot convert list [1, 2] to an int", 2},
+		{"to-float of text", "main {\n  @return to-float(\"x\");\n}", "cannot convert string", 2},
+		{"to-bool of a number", "main {\n  @return to-bool(1);\n}", "cannot convert int 1", 2},
+	})
+}
@@ -2,6 +2,7 @@ package interpreter
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/shreyassanthu77/cisp/ast"
 )
@@ -29,33 +30,95 @@ func evalUnaryOp(op ast.UnaryOp, env *Environment) (ast.Value, error) {
 		default:
 			return ast.NilValue{}, fmt.Errorf("invalid type for unary operator !: %T", val)
 		}
+	case "~":
+		switch val := val.(type) {
+		case ast.Int:
+			return ast.Int{Value: ^val.Value}, nil
+		default:
+			return ast.NilValue{}, fmt.Errorf("invalid type for unary operator ~: %T", val)
+		}
 	}
 
 	return ast.NilValue{}, fmt.Errorf("invalid unary operator %s", op.Op)
 }
 
 func evalValue(value ast.Value, env *Environment) (ast.Value, error) {
+	res, err := evalValueNode(value, env)
+	if err != nil {
+		return ast.NilValue{}, withSpan(err, value.GetSpan())
+	}
+	return res, nil
+}
+
+func evalValueNode(value ast.Value, env *Environment) (ast.Value, error) {
 	switch value := value.(type) {
 	case ast.FunctionCall:
 		return evalFnCall(value, env)
 	case ast.Identifier:
 		_, err := env.getVar(value.Name)
 		if err != nil {
-			_, err := env.genFn(value.Name)
+			fn, err := functionValue(value.Name, value.Span, env)
 			if err == nil {
-				return ast.NilValue{}, fmt.Errorf("You cannot use a function as a value use %s() instead of %s if you want to call it", value.Name, value.Name)
+				return fn, nil
 			}
 			return ast.NilValue{}, fmt.Errorf("Literal Identifiers are not allowed use $variable if you want to use a variable")
 		}
 		return ast.NilValue{}, fmt.Errorf("Literal Identifiers are not allowed use $%s instead of %s", value.Name, value.Name)
-	case ast.Int, ast.Float, ast.String, ast.Boolean, ast.NilValue:
+	case ast.Int, ast.Float, ast.String, ast.Boolean, ast.NilValue, RecordValue, EnumValue, FunctionValue:
 		return value, nil
+	case ast.List:
+		values := make([]ast.Value, len(value.Values))
+		for i, item := range value.Values {
+			val, err := evalValue(item, env)
+			if err != nil {
+				return ast.NilValue{}, err
+			}
+			values[i] = val
+		}
+		return ast.List{Values: values, Span: value.Span}, nil
+	case ast.Map:
+		entries := make([]ast.MapEntry, 0, len(value.Entries))
+		for _, entry := range value.Entries {
+			key, err := evalValue(entry.Key, env)
+			if err != nil {
+				return ast.NilValue{}, err
+			}
+
+			if _, ok := mapIndex(entries, key); ok {
+				return ast.NilValue{}, runtimeError(entry.Key.GetSpan(), "duplicate key %s in map", stringifyNested(key))
+			}
+
+			val, err := evalValue(entry.Value, env)
+			if err != nil {
+				return ast.NilValue{}, err
+			}
+			entries = append(entries, ast.MapEntry{Key: key, Value: val})
+		}
+		return ast.Map{Entries: entries, Span: value.Span}, nil
+	case ast.InterpolatedString:
+		sb := strings.Builder{}
+		for _, part := range value.Parts {
+			val, err := evalValue(part, env)
+			if err != nil {
+				return ast.NilValue{}, err
+			}
+			sb.WriteString(stringify(val))
+		}
+		return ast.String{Value: sb.String(), Span: value.Span}, nil
+	case ast.Match:
+		return evalMatchValue(value, env)
+	case ast.AnonymousRule:
+		return evalAnonymousRule(value, env)
 	case ast.UnaryOp:
 		return evalUnaryOp(value, env)
 	case ast.BinaryOp:
 		return evalBinaryOp(value, env)
 	case ast.VarianleDerefValue:
-		val, err := env.getVar(value.Variable.Name)
+		name := value.Variable.Name
+		val, err := env.getVar(name)
+		if err != nil && strings.Contains(name, ".") {
+			return evalFieldAccess(name, env)
+		}
 		if err != nil {
 			return ast.NilValue{}, err
 		}
@@ -65,7 +128,29 @@ func evalValue(value ast.Value, env *Environment) (ast.Value, error) {
 	return ast.NilValue{}, fmt.Errorf("invalid value type: %T", value)
 }
 
+// evalSpecialForm evaluates the builtins that need their arguments
+// unevaluated. It reports false when fnCall is an ordinary call.
+func evalSpecialForm(fnCall ast.FunctionCall, env *Environment) (ast.Value, bool, error) {
+	switch fnCall.Fn.Name {
+	case "attr":
+		res, err := evalAttrCall(fnCall, env)
+		return res, true, err
+	case "fn":
+		res, err := evalFnRefCall(fnCall, env)
+		return res, true, err
+	case "call":
+		res, err := evalCallCall(fnCall, env)
+		return res, true, err
+	}
+	return nil, false, nil
+}
+
 func evalFnCall(fnCall ast.FunctionCall, env *Environment) (ast.Value, error) {
+	res, ok, err := evalSpecialForm(fnCall, env)
+	if ok {
+		return res, err
+	}
+
 	fn, err := env.genFn(fnCall.Fn.Name)
 	if err != nil {
 		return ast.NilValue{}, err
@@ -79,5 +164,9 @@ func evalFnCall(fnCall ast.FunctionCall, env *Environment) (ast.Value, error) {
 		}
 	}
 
-	return evalRule(fn, params, env)
+	res, err = callFn(fn, params, fnCall.Span)
+	if err != nil {
+		return ast.NilValue{}, pushFrame(err, fnCall.Fn.Name, fnCall.Span)
+	}
+	return res, nil
 }
@@ -1,8 +1,14 @@
 package lexer
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
 
 type Lexer struct {
+	file  string
 	input string
 	pos   int
 	line  int
@@ -11,7 +17,13 @@ type Lexer struct {
 }
 
 func New(input string) *Lexer {
+	return NewFile("", input)
+}
+
+// NewFile creates a lexer whose spans remember the file they come from.
+func NewFile(file string, input string) *Lexer {
 	return &Lexer{
+		file:  file,
 		input: input,
 		pos:   0,
 		line:  1,
@@ -30,6 +42,7 @@ func (l *Lexer) loc() Loc {
 
 func (l *Lexer) span(start Loc) Span {
 	return Span{
+		File:  l.file,
 		Start: start,
 		End:   l.loc(),
 	}
@@ -43,9 +56,18 @@ func (l *Lexer) tok(typ string, value string, start Loc) Token {
 	}
 }
 
-func (l *Lexer) error(format string, args ...interface{}) error {
-	pre := fmt.Sprintf("{%d:%d} ", l.line, l.col)
-	return fmt.Errorf(pre+format, args...)
+func (l *Lexer) error(start Loc, format string, args ...interface{}) error {
+	return Error{
+		Span:    l.span(start),
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (l *Lexer) errorAt(span Span, format string, args ...interface{}) error {
+	return Error{
+		Span:    span,
+		Message: fmt.Sprintf(format, args...),
+	}
 }
 
 func (l *Lexer) peek() string {
@@ -67,8 +89,12 @@ func (l *Lexer) next() string {
 		return EOF
 	}
 
+	// Columns count characters, the bytes that continue a multibyte
+	// character don't move to the next column
+	if utf8.RuneStart(l.input[l.pos]) {
+		l.col++
+	}
 	l.pos++
-	l.col++
 
 	nextCh := l.peek()
 	if ch == "\n" || ch == "\r" {
@@ -82,43 +108,314 @@ func (l *Lexer) next() string {
 	return ch
 }
 
-func (l *Lexer) skipWhitespace() {
-	if l.done {
-		return
+func (l *Lexer) peekAt(offset int) string {
+	if l.pos+offset >= len(l.input) {
+		return EOF
 	}
+
+	return string(l.input[l.pos+offset])
+}
+
+func isWhitespace(ch string) bool {
+	return ch == " " || ch == "\t" || ch == "\n" || ch == "\r"
+}
+
+// readWhitespace consumes a run of whitespace. When stopAtNewline is set it
+// stops right after the first line break and reports that it saw one.
+func (l *Lexer) readWhitespace(stopAtNewline bool) bool {
 	for {
 		ch := l.peek()
+		if ch == EOF || !isWhitespace(ch) {
+			return false
+		}
+
+		l.next()
+		if stopAtNewline && (ch == "\n" || ch == "\r") {
+			return true
+		}
+	}
+}
+
+func (l *Lexer) readBlockComment(loc Loc) (Trivia, error) {
+	l.next() // Consume '/'
+	l.next() // Consume '*'
+	for {
+		ch := l.next()
 		if ch == EOF {
-			break
+			return Trivia{}, l.error(loc, "Unterminated comment")
 		}
 
-		if ch != " " && ch != "\t" && ch != "\n" && ch != "\r" {
+		if ch == "*" && l.peek() == "/" {
+			l.next()
 			break
 		}
+	}
+
+	return Trivia{
+		Typ:   TRIVIA_BLOCK_COMMENT,
+		Value: l.input[loc.Pos:l.pos],
+		Span:  l.span(loc),
+	}, nil
+}
 
+func (l *Lexer) readLineComment(loc Loc) Trivia {
+	for {
+		ch := l.peek()
+		if ch == EOF || ch == "\n" || ch == "\r" {
+			break
+		}
 		l.next()
 	}
+
+	return Trivia{
+		Typ:   TRIVIA_LINE_COMMENT,
+		Value: l.input[loc.Pos:l.pos],
+		Span:  l.span(loc),
+	}
 }
 
-func (l *Lexer) readString(quote string, loc Loc) (Token, error) {
-	if l.done {
-		return Token{}, l.error("Unexpected EOF")
+// readTrivia collects whitespace and comments. Trailing trivia ends at the
+// first line break so that everything on the following lines becomes the
+// leading trivia of the next token.
+func (l *Lexer) readTrivia(trailing bool) ([]Trivia, error) {
+	trivia := []Trivia{}
+	for {
+		loc := l.loc()
+		ch := l.peek()
+
+		switch {
+		case ch == EOF:
+			return trivia, nil
+		case isWhitespace(ch):
+			newline := l.readWhitespace(trailing)
+			trivia = append(trivia, Trivia{
+				Typ:   TRIVIA_WHITESPACE,
+				Value: l.input[loc.Pos:l.pos],
+				Span:  l.span(loc),
+			})
+			if newline {
+				return trivia, nil
+			}
+		case ch == "/" && l.peekAt(1) == "*":
+			comment, err := l.readBlockComment(loc)
+			if err != nil {
+				return nil, err
+			}
+			trivia = append(trivia, comment)
+		case ch == "/" && l.peekAt(1) == "/":
+			trivia = append(trivia, l.readLineComment(loc))
+		default:
+			return trivia, nil
+		}
 	}
+}
 
-	start := l.pos
+// readString reads a string literal, interpreting escapes: `\"`, `\\`, `\n`,
+// `\t`, a code point like `\1F600` and a backslash before a newline, which
+// continues the string on the next line without including the newline.
+func (l *Lexer) readString(quote string, loc Loc) (Token, error) {
+	sb := strings.Builder{}
+	parts := []StringPart{}
+	text := strings.Builder{}
+	// A bad escape or interpolation is reported once the whole string has
+	// been read so that lexing carries on after it
+	var partErr error
 	for {
-		ch := l.next()
+		ch := l.peek()
 		if ch == EOF {
-			return Token{}, l.error("Unexpected EOF")
+			return Token{}, l.errorAt(l.charSpan(loc), "Unterminated string")
 		}
 
 		if ch == quote {
+			l.next()
 			break
 		}
+
+		if ch == "#" && l.peekAt(1) == "{" {
+			start := l.pos
+			expr, err := l.readInterpolation()
+			if err != nil {
+				if partErr == nil {
+					partErr = err
+				}
+				continue
+			}
+
+			parts = append(parts, StringPart{Text: text.String()}, StringPart{Expr: &expr})
+			text.Reset()
+			sb.WriteString(l.input[start:l.pos])
+			continue
+		}
+
+		if ch != "\\" {
+			// Copy bytes, not peeked characters, to keep utf-8 intact. next
+			// consumes a whole \r\n line break
+			start := l.pos
+			raw := l.input[start : start+1]
+			if l.next() == "\r" {
+				raw = "\n"
+			}
+			sb.WriteString(raw)
+			text.WriteString(raw)
+			continue
+		}
+
+		escape, err := l.readEscape()
+		if err != nil && partErr == nil {
+			partErr = err
+		}
+		sb.WriteString(escape)
+		text.WriteString(escape)
 	}
 
-	str := l.input[start : l.pos-1]
-	return l.tok(TOK_STRING, str, loc), nil
+	if partErr != nil {
+		return Token{}, partErr
+	}
+
+	tok := l.tok(TOK_STRING, sb.String(), loc)
+	if len(parts) > 0 {
+		tok.Parts = append(parts, StringPart{Text: text.String()})
+	}
+	return tok, nil
+}
+
+// readInterpolation skips over a `#{...}` inside a string and returns the
+// span of the expression between the braces.
+func (l *Lexer) readInterpolation() (Span, error) {
+	start := l.loc()
+	l.next() // Consume '#'
+	l.next() // Consume '{'
+
+	exprStart := l.loc()
+	depth := 0
+	for {
+		ch := l.peek()
+		switch ch {
+		case EOF:
+			return Span{}, l.errorAt(Span{File: l.file, Start: start, End: exprStart}, "Unterminated interpolation, expected `}`")
+		case "\"", "'":
+			// Strings inside the expression can contain braces
+			quote := l.loc()
+			l.next()
+			_, err := l.readString(ch, quote)
+			if err != nil {
+				return Span{}, err
+			}
+			continue
+		case "{":
+			depth++
+		case "}":
+			if depth == 0 {
+				expr := Span{File: l.file, Start: exprStart, End: l.loc()}
+				l.next()
+				if expr.End.Pos == expr.Start.Pos {
+					return Span{}, l.errorAt(l.span(start), "Empty interpolation")
+				}
+				return expr, nil
+			}
+			depth--
+		}
+		l.next()
+	}
+}
+
+// Sub creates a lexer over the source covered by span, the tokens it reads
+// keep their position in the whole input.
+func (l *Lexer) Sub(span Span) *Lexer {
+	return &Lexer{
+		file:  l.file,
+		input: l.input[:span.End.Pos],
+		pos:   span.Start.Pos,
+		line:  span.Start.Line,
+		col:   span.Start.Col,
+	}
+}
+
+// readEscape reads an escape sequence inside a string, starting at its
+// backslash, and returns the text it stands for.
+func (l *Lexer) readEscape() (string, error) {
+	start := l.loc()
+	l.next() // Consume '\\'
+
+	ch := l.peek()
+	switch {
+	case ch == EOF:
+		return "", l.errorAt(l.charSpan(start), "Unterminated escape sequence")
+	case ch == "n":
+		l.next()
+		return "\n", nil
+	case ch == "t":
+		l.next()
+		return "\t", nil
+	case ch == "\n":
+		l.next()
+		return "", nil
+	case ch == "\r":
+		// next consumes a whole \r\n line break
+		l.next()
+		return "", nil
+	case isHexDigit(ch):
+		return l.readCodePointEscape(start)
+	}
+
+	// Like css, any other escaped character stands for itself
+	_, size := utf8.DecodeRuneInString(l.input[l.pos:])
+	escaped := l.input[l.pos : l.pos+size]
+	for i := 0; i < size; i++ {
+		l.next()
+	}
+	return escaped, nil
+}
+
+// readCodePointEscape reads the up to 6 hex digits of an escape like `\e9`.
+// A single whitespace after the digits ends the escape and is dropped.
+func (l *Lexer) readCodePointEscape(start Loc) (string, error) {
+	digits := ""
+	for len(digits) < 6 && isHexDigit(l.peek()) {
+		digits += l.next()
+	}
+
+	end := l.loc()
+	if ch := l.peek(); ch == " " || ch == "\t" || ch == "\n" {
+		l.next()
+	}
+
+	codePoint, _ := strconv.ParseUint(digits, 16, 32)
+	r := rune(codePoint)
+	if codePoint == 0 || !utf8.ValidRune(r) {
+		span := Span{File: l.file, Start: start, End: end}
+		return "", l.errorAt(span, "Invalid code point U+%s in escape sequence", strings.ToUpper(digits))
+	}
+	return string(r), nil
+}
+
+func isHexDigit(ch string) bool {
+	return (ch >= "0" && ch <= "9") || (ch >= "a" && ch <= "f") || (ch >= "A" && ch <= "F")
+}
+
+// charSpan is the span of the single character at loc.
+func (l *Lexer) charSpan(loc Loc) Span {
+	return Span{
+		File:  l.file,
+		Start: loc,
+		End: Loc{
+			Pos:  loc.Pos + 1,
+			Line: loc.Line,
+			Col:  loc.Col + 1,
+		},
+	}
+}
+
+// hasKeyword consumes keyword if the input continues with it as a whole word.
+func (l *Lexer) hasKeyword(keyword string) bool {
+	if !strings.HasPrefix(l.input[l.pos:], keyword) || isValidIdentifier(l.peekAt(len(keyword))) {
+		return false
+	}
+
+	for range keyword {
+		l.next()
+	}
+	return true
 }
 
 func isValidIdentifierStart(ch string) bool {
@@ -130,10 +427,6 @@ func isValidIdentifier(ch string) bool {
 }
 
 func (l *Lexer) readIdentifier(loc Loc) (Token, error) {
-	if l.done {
-		return Token{}, l.error("Unexpected EOF")
-	}
-
 	start := l.pos - 1 // -1 because we already read the first char
 	for {
 		ch := l.peek()
@@ -165,10 +458,6 @@ func isValidNumber(ch string) bool {
 }
 
 func (l *Lexer) readNumber(ch string, loc Loc) (Token, error) {
-	if l.done {
-		return Token{}, l.error("Unexpected EOF")
-	}
-
 	start := l.pos - 1 // -1 because we already read the first char
 	deci := ch == "."
 	for {
@@ -179,7 +468,7 @@ func (l *Lexer) readNumber(ch string, loc Loc) (Token, error) {
 
 		if ch == "." {
 			if deci {
-				l.error("Unexpected character: `.` after `.` in number is that a typo?")
+				l.error(l.loc(), "Unexpected character: `.` after `.` in number is that a typo?")
 			}
 			deci = true
 			l.next()
@@ -202,8 +491,27 @@ func (l *Lexer) readNumber(ch string, loc Loc) (Token, error) {
 }
 
 func (l *Lexer) Next() (Token, error) {
-	l.skipWhitespace()
+	leading, err := l.readTrivia(false)
+	if err != nil {
+		return Token{}, err
+	}
 
+	tok, err := l.readToken()
+	if err != nil {
+		return Token{}, err
+	}
+
+	trailing, err := l.readTrivia(true)
+	if err != nil {
+		return Token{}, err
+	}
+
+	tok.Leading = leading
+	tok.Trailing = trailing
+	return tok, nil
+}
+
+func (l *Lexer) readToken() (Token, error) {
 	loc := l.loc()
 	ch := l.next()
 
@@ -218,8 +526,15 @@ func (l *Lexer) Next() (Token, error) {
 			l.next()
 			return l.tok(TOK_NOT_EQUAL, ch+nextCh, loc), nil
 		}
+		if l.hasKeyword("important") {
+			return l.tok(TOK_IMPORTANT, l.input[loc.Pos:l.pos], loc), nil
+		}
 		return l.tok(TOK_BANG, ch, loc), nil
 	case "~":
+		if nextCh == "=" {
+			l.next()
+			return l.tok(TOK_INCLUDES_MATCH, ch+nextCh, loc), nil
+		}
 		return l.tok(TOK_TILDE, ch, loc), nil
 	case "+":
 		return l.tok(TOK_PLUS, ch, loc), nil
@@ -229,12 +544,20 @@ func (l *Lexer) Next() (Token, error) {
 		}
 		return l.tok(TOK_MINUS, ch, loc), nil
 	case "*":
+		if nextCh == "=" {
+			l.next()
+			return l.tok(TOK_SUBSTRING_MATCH, ch+nextCh, loc), nil
+		}
 		return l.tok(TOK_ASTERISK, ch, loc), nil
 	case "/":
 		return l.tok(TOK_SLASH, ch, loc), nil
 	case "%":
 		return l.tok(TOK_PERCENT, ch, loc), nil
 	case "^":
+		if nextCh == "=" {
+			l.next()
+			return l.tok(TOK_PREFIX_MATCH, ch+nextCh, loc), nil
+		}
 		return l.tok(TOK_CARET, ch, loc), nil
 	case "=":
 		if nextCh == "=" {
@@ -243,12 +566,20 @@ func (l *Lexer) Next() (Token, error) {
 		}
 		return l.tok(TOK_EQUAL, ch, loc), nil
 	case ">":
+		if nextCh == ">" {
+			l.next()
+			return l.tok(TOK_SHIFT_RIGHT, ch+nextCh, loc), nil
+		}
 		if nextCh == "=" {
 			l.next()
 			return l.tok(TOK_GREATER_THAN_EQUAL, ch+nextCh, loc), nil
 		}
 		return l.tok(TOK_GREATER_THAN, ch, loc), nil
 	case "<":
+		if nextCh == "<" {
+			l.next()
+			return l.tok(TOK_SHIFT_LEFT, ch+nextCh, loc), nil
+		}
 		if nextCh == "=" {
 			l.next()
 			return l.tok(TOK_LESS_THAN_EQUAL, ch+nextCh, loc), nil
@@ -259,14 +590,22 @@ func (l *Lexer) Next() (Token, error) {
 			l.next()
 			return l.tok(TOK_AND, ch+nextCh, loc), nil
 		}
-		return Token{}, l.error("Unexpected character: `&` did you mean `&&` ?")
+		return l.tok(TOK_AMPERSAND, ch, loc), nil
 	case "|":
 		if nextCh == "|" {
 			l.next()
 			return l.tok(TOK_OR, ch+nextCh, loc), nil
 		}
-		return Token{}, l.error("Unexpected character: `|` did you mean `||` ?")
+		if nextCh == "=" {
+			l.next()
+			return l.tok(TOK_DASH_MATCH, ch+nextCh, loc), nil
+		}
+		return l.tok(TOK_PIPE, ch, loc), nil
 	case "$":
+		if nextCh == "=" {
+			l.next()
+			return l.tok(TOK_SUFFIX_MATCH, ch+nextCh, loc), nil
+		}
 		return l.tok(TOK_DOLLAR, ch, loc), nil
 	case "(":
 		if nextCh == ")" {
@@ -317,5 +656,5 @@ func (l *Lexer) Next() (Token, error) {
 		return l.readNumber(ch, loc)
 	}
 
-	return Token{}, l.error("Unexpected character: `%s`", ch)
+	return Token{}, l.error(loc, "Unexpected character: `%s`", ch)
 }
@@ -0,0 +1,202 @@
+package lexer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestColumnsCountCharacters(t *testing.T) {
+	lex := New(`"日本" x`)
+	if _, err := lex.Next(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tok, err := lex.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if tok.Span.Start.Col != 6 {
+		t.Errorf("x starts at column %d, want 6", tok.Span.Start.Col)
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", `"abc"`, "abc"},
+		{"quote", `"a\"b"`, `a"b`},
+		{"other quote", `'a\'b'`, "a'b"},
+		{"backslash", `"a\\b"`, `a\b`},
+		{"newline", `"a\nb"`, "a\nb"},
+		{"tab", `"a\tb"`, "a\tb"},
+		{"code point", `"\41"`, "A"},
+		{"code point eats one space", `"\41 B"`, "AB"},
+		{"code point keeps the second space", `"\41  B"`, "A B"},
+		{"six digit code point", `"\01F600"`, "😀"},
+		{"seventh digit is text", `"\0000411"`, "A1"},
+		{"line continuation", "\"a\\\nb\"", "ab"},
+		{"crlf continuation", "\"a\\\r\nb\"", "ab"},
+		{"escaped character", `"\z"`, "z"},
+		{"escaped multibyte character", `"\é"`, "é"},
+		{"multibyte text", `"héllo 日本"`, "héllo 日本"},
+		{"multi-line", "\"a\nb\"", "a\nb"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tok, err := New(test.input).Next()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tok.Typ != TOK_STRING {
+				t.Fatalf("got a %s token, want a string", tok.Typ)
+			}
+			if tok.Value != test.want {
+				t.Errorf("got %q, want %q", tok.Value, test.want)
+			}
+		})
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		message string
+		start   Loc
+		end     Loc
+	}{
+		{
+			name:    "unterminated string",
+			input:   `--x: "abc`,
+			message: "Unterminated string",
+			start:   Loc{Pos: 5, Line: 1, Col: 6},
+			end:     Loc{Pos: 6, Line: 1, Col: 7},
+		},
+		{
+			name:    "unterminated string after multibyte text",
+			input:   `"é" "abc`,
+			message: "Unterminated string",
+			start:   Loc{Pos: 5, Line: 1, Col: 5},
+			end:     Loc{Pos: 6, Line: 1, Col: 6},
+		},
+		{
+			name:    "unterminated string on a later line",
+			input:   "a\n  'abc\ndef",
+			message: "Unterminated string",
+			start:   Loc{Pos: 4, Line: 2, Col: 3},
+			end:     Loc{Pos: 5, Line: 2, Col: 4},
+		},
+		{
+			name:    "backslash at the end of the input",
+			input:   `"abc\`,
+			message: "Unterminated string",
+			start:   Loc{Pos: 0, Line: 1, Col: 1},
+			end:     Loc{Pos: 1, Line: 1, Col: 2},
+		},
+		{
+			name:    "invalid code point",
+			input:   `"a\110000 b"`,
+			message: "Invalid code point U+110000 in escape sequence",
+			start:   Loc{Pos: 2, Line: 1, Col: 3},
+			end:     Loc{Pos: 9, Line: 1, Col: 10},
+		},
+		{
+			name:    "null code point",
+			input:   `"\0"`,
+			message: "Invalid code point U+0 in escape sequence",
+			start:   Loc{Pos: 1, Line: 1, Col: 2},
+			end:     Loc{Pos: 3, Line: 1, Col: 4},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lex := New(test.input)
+			var err error
+			for err == nil {
+				var tok Token
+				tok, err = lex.Next()
+				if err == nil && tok.Typ == EOF {
+					t.Fatal("expected an error")
+				}
+			}
+
+			var lexErr Error
+			if !errors.As(err, &lexErr) {
+				t.Fatalf("got %T, want a lexer error", err)
+			}
+			if lexErr.Message != test.message {
+				t.Errorf("got message %q, want %q", lexErr.Message, test.message)
+			}
+			if lexErr.Span.Start != test.start || lexErr.Span.End != test.end {
+				t.Errorf("got span %+v-%+v, want %+v-%+v", lexErr.Span.Start, lexErr.Span.End, test.start, test.end)
+			}
+		})
+	}
+}
+
+func TestInterpolationParts(t *testing.T) {
+	input := `"a\n#{$b + "}"} c"`
+	tok, err := New(input).Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(tok.Parts) != 3 {
+		t.Fatalf("got %d parts, want 3", len(tok.Parts))
+	}
+	if tok.Parts[0].Text != "a\n" || tok.Parts[2].Text != " c" {
+		t.Errorf("got the text parts %q and %q", tok.Parts[0].Text, tok.Parts[2].Text)
+	}
+	expr := tok.Parts[1].Expr
+	if expr == nil || input[expr.Start.Pos:expr.End.Pos] != `$b + "}"` {
+		t.Errorf("got the expression %+v, want the source of $b + \"}\"", expr)
+	}
+
+	plain, err := New(`"a \#{b}"`).Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if plain.Parts != nil || plain.Value != "a #{b}" {
+		t.Errorf("got %q with parts %+v, want an escaped #{", plain.Value, plain.Parts)
+	}
+}
+
+func TestUnterminatedInterpolation(t *testing.T) {
+	_, err := New(`"a #{$b"`).Next()
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestOperators(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"a << b", []string{TOK_IDENTIFIER, TOK_SHIFT_LEFT, TOK_IDENTIFIER}},
+		{"a >> b", []string{TOK_IDENTIFIER, TOK_SHIFT_RIGHT, TOK_IDENTIFIER}},
+		{"a <= b", []string{TOK_IDENTIFIER, TOK_LESS_THAN_EQUAL, TOK_IDENTIFIER}},
+		{"a | b || c", []string{TOK_IDENTIFIER, TOK_PIPE, TOK_IDENTIFIER, TOK_OR, TOK_IDENTIFIER}},
+		{"a & b && c", []string{TOK_IDENTIFIER, TOK_AMPERSAND, TOK_IDENTIFIER, TOK_AND, TOK_IDENTIFIER}},
+		{"a ^ b ^= c", []string{TOK_IDENTIFIER, TOK_CARET, TOK_IDENTIFIER, TOK_PREFIX_MATCH, TOK_IDENTIFIER}},
+		{"a |= b", []string{TOK_IDENTIFIER, TOK_DASH_MATCH, TOK_IDENTIFIER}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			lex := New(test.input)
+			for i, want := range append(test.want, EOF) {
+				tok, err := lex.Next()
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if tok.Typ != want {
+					t.Fatalf("token %d is a %s, want a %s", i, tok.Typ, want)
+				}
+			}
+		})
+	}
+}
@@ -32,6 +32,10 @@ const (
 	TOK_AND                = "AND"
 	TOK_OR                 = "OR"
 	TOK_DOLLAR             = "DOLLAR"
+	TOK_AMPERSAND          = "AMPERSAND"
+	TOK_PIPE               = "PIPE"
+	TOK_SHIFT_LEFT         = "SHIFT_LEFT"
+	TOK_SHIFT_RIGHT        = "SHIFT_RIGHT"
 
 	// Expressions
 	TOK_LPAREN = "LPAREN"
@@ -45,6 +49,13 @@ const (
 	TOK_LBRACKET = "LBRACKET"
 	TOK_RBRACKET = "RBRACKET"
 
+	// Attribute selector operators
+	TOK_PREFIX_MATCH    = "PREFIX_MATCH"    // ^=
+	TOK_SUFFIX_MATCH    = "SUFFIX_MATCH"    // $=
+	TOK_SUBSTRING_MATCH = "SUBSTRING_MATCH" // *=
+	TOK_DASH_MATCH      = "DASH_MATCH"      // |=
+	TOK_INCLUDES_MATCH  = "INCLUDES_MATCH"  // ~=
+
 	// rules
 	TOK_AT        = "AT"
 	TOK_LSQUIRLY  = "LSQUIRLY"
@@ -61,14 +72,50 @@ type Loc struct {
 }
 
 type Span struct {
+	// File is the path of the source the span points into, empty when the
+	// source didn't come from a file.
+	File  string
 	Start Loc
 	End   Loc
 }
 
+const (
+	TRIVIA_WHITESPACE    = "WHITESPACE"
+	TRIVIA_BLOCK_COMMENT = "BLOCK_COMMENT" // /* ... */
+	TRIVIA_LINE_COMMENT  = "LINE_COMMENT"  // // ...
+)
+
+// Trivia is source text that carries no meaning for the parser, whitespace
+// and comments, kept around so tooling can reproduce the original source.
+type Trivia struct {
+	Typ   string
+	Value string
+	Span  Span
+}
+
 type Token struct {
 	Typ   string
 	Value string
 	Span  Span
+
+	// Leading holds the trivia between the previous token's trailing trivia
+	// and this token. Trailing holds the trivia after this token up to and
+	// including the end of the line.
+	Leading  []Trivia
+	Trailing []Trivia
+
+	// Parts splits a string containing interpolations, `"a #{$b} c"`, into
+	// its text and the expressions between `#{` and `}`. It is nil for
+	// strings without interpolation and for other tokens.
+	Parts []StringPart
+}
+
+// StringPart is a piece of an interpolated string. Text holds literal text
+// with its escapes interpreted, Expr the span of an interpolated
+// expression's source.
+type StringPart struct {
+	Text string
+	Expr *Span
 }
 
 func (t Token) String() string {
@@ -76,3 +123,13 @@ func (t Token) String() string {
 	col := t.Span.Start.Col
 	return fmt.Sprintf("{%d:%d} TOK<%s>(%s)", line, col, t.Typ, t.Value)
 }
+
+// Error is a lexing error pointing at the offending source text.
+type Error struct {
+	Span    Span
+	Message string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("{%d:%d} %s", e.Span.Start.Line, e.Span.Start.Col, e.Message)
+}
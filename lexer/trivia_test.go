@@ -0,0 +1,77 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+// tokens lexes the whole input, EOF included.
+func tokens(t *testing.T, input string) []Token {
+	t.Helper()
+	lex := New(input)
+	toks := []Token{}
+	for {
+		tok, err := lex.Next()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		toks = append(toks, tok)
+		if tok.Typ == EOF {
+			return toks
+		}
+	}
+}
+
+func TestTriviaRoundTrip(t *testing.T) {
+	inputs := []string{
+		"",
+		"main { print: 1; }",
+		"/* header */\nmain {\n  // a comment\n  --x: 1; /* trailing */ // and a line\n}\n",
+		"a/**/b//c\n\t\n  /* multi\nline */ d",
+		"// only a comment",
+		"--s: \"a /* not a comment */ b\"; // but this is\r\n",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			var out strings.Builder
+			for _, tok := range tokens(t, input) {
+				for _, trivia := range tok.Leading {
+					out.WriteString(trivia.Value)
+				}
+				out.WriteString(input[tok.Span.Start.Pos:tok.Span.End.Pos])
+				for _, trivia := range tok.Trailing {
+					out.WriteString(trivia.Value)
+				}
+			}
+			if out.String() != input {
+				t.Errorf("got %q, want %q", out.String(), input)
+			}
+		})
+	}
+}
+
+func TestTrivia(t *testing.T) {
+	toks := tokens(t, "/* doc */\nmain // name\n{ }")
+
+	main := toks[0]
+	if len(main.Leading) != 2 || main.Leading[0].Typ != TRIVIA_BLOCK_COMMENT || main.Leading[0].Value != "/* doc */" {
+		t.Errorf("got the leading trivia %+v, want the block comment and a newline", main.Leading)
+	}
+	if len(main.Trailing) != 3 || main.Trailing[1].Typ != TRIVIA_LINE_COMMENT || main.Trailing[1].Value != "// name" {
+		t.Errorf("got the trailing trivia %+v, want the line comment up to the end of the line", main.Trailing)
+	}
+
+	lbrace := toks[1]
+	if lbrace.Typ != TOK_LSQUIRLY || len(lbrace.Leading) != 0 {
+		t.Errorf("got %s with the leading trivia %+v, want { without any", lbrace.Typ, lbrace.Leading)
+	}
+}
+
+func TestUnterminatedComment(t *testing.T) {
+	// The comment is trailing trivia of a, so lexing a fails
+	_, err := New("a /* b").Next()
+	if err == nil || !strings.Contains(err.Error(), "Unterminated comment") {
+		t.Errorf("got %v, want an unterminated comment error", err)
+	}
+}
@@ -0,0 +1,233 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/diagnostic"
+	"github.com/shreyassanthu77/cisp/lexer"
+	"github.com/shreyassanthu77/cisp/parser"
+)
+
+// Module is a parsed source file together with the modules it imports.
+type Module struct {
+	Path    string
+	Source  string
+	Program ast.Program
+	// Imports maps the path written in a `@use` or `@import` rule to the
+	// module it resolved to.
+	Imports map[string]*Module
+}
+
+// Loader reads, parses and caches the modules of a program. Every file is
+// parsed once no matter how many modules import it.
+type Loader struct {
+	// SearchPath lists the directories tried, in order, when an import
+	// isn't found next to the file importing it.
+	SearchPath []string
+
+	modules     map[string]*Module
+	order       []*Module
+	loading     []string
+	diagnostics []diagnostic.Diagnostic
+}
+
+func New(searchPath ...string) *Loader {
+	return &Loader{
+		SearchPath: searchPath,
+		modules:    make(map[string]*Module),
+	}
+}
+
+// IsImport reports whether rule loads another module.
+func IsImport(rule ast.AtRule) bool {
+	return rule.Name == "use" || rule.Name == "import"
+}
+
+// ImportPath returns the path a `@use` or `@import` rule refers to.
+func ImportPath(rule ast.AtRule) (string, error) {
+	if len(rule.Parameters) == 0 {
+		return "", diagnostic.Errorf(rule.Span, "@%s needs the path of a module", rule.Name)
+	}
+
+	path, ok := rule.Parameters[0].(ast.String)
+	if !ok {
+		return "", diagnostic.Errorf(rule.Parameters[0].GetSpan(), "the path of a module must be a string")
+	}
+	return path.Value, nil
+}
+
+// Load parses the file at path and every module it imports. The diagnostics
+// of all the files are returned together, their spans tell which file they
+// belong to. The error is only set when path itself can't be read.
+func (l *Loader) Load(path string) (*Module, []diagnostic.Diagnostic, error) {
+	mod, err := l.load(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mod, l.diagnostics, nil
+}
+
+// Modules returns every module loaded so far, each after the modules it
+// imports.
+func (l *Loader) Modules() []*Module {
+	return l.order
+}
+
+// Sources returns the contents of every loaded file keyed by path, for
+// rendering diagnostics that point into imported modules.
+func (l *Loader) Sources() map[string]string {
+	sources := make(map[string]string, len(l.order))
+	for _, mod := range l.order {
+		sources[mod.Path] = mod.Source
+	}
+	return sources
+}
+
+func (l *Loader) report(err error) {
+	l.diagnostics = append(l.diagnostics, diagnostic.FromError(err))
+}
+
+func (l *Loader) load(path string) (*Module, error) {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if mod, ok := l.modules[key]; ok {
+		return mod, nil
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	par := parser.New(lexer.NewFile(path, string(source)))
+	program, diags := par.Parse()
+	l.diagnostics = append(l.diagnostics, diags...)
+
+	mod := &Module{
+		Path:    path,
+		Source:  string(source),
+		Program: program,
+		Imports: make(map[string]*Module),
+	}
+
+	l.loading = append(l.loading, key)
+	defer func() {
+		l.loading = l.loading[:len(l.loading)-1]
+	}()
+
+	for _, rule := range program.Rules {
+		rule, ok := rule.(ast.AtRule)
+		if !ok || !IsImport(rule) {
+			continue
+		}
+
+		err := l.loadImport(mod, rule)
+		if err != nil {
+			l.report(err)
+		}
+	}
+
+	l.modules[key] = mod
+	l.order = append(l.order, mod)
+	return mod, nil
+}
+
+func (l *Loader) loadImport(mod *Module, rule ast.AtRule) error {
+	name, err := ImportPath(rule)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := mod.Imports[name]; ok {
+		return nil
+	}
+
+	path, err := l.resolve(mod.Path, name)
+	if err != nil {
+		return diagnostic.Errorf(rule.Span, "%s", err).
+			WithNote("searched next to %s and in %s", mod.Path, l.searchPathString())
+	}
+
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return diagnostic.Errorf(rule.Span, "%s", err)
+	}
+
+	for i, loading := range l.loading {
+		if loading != key {
+			continue
+		}
+
+		cycle := []string{}
+		for _, file := range l.loading[i:] {
+			cycle = append(cycle, l.relative(file))
+		}
+		cycle = append(cycle, l.relative(key))
+		return diagnostic.Errorf(rule.Span, "import cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	imported, err := l.load(path)
+	if err != nil {
+		return diagnostic.Errorf(rule.Span, "%s", err)
+	}
+
+	mod.Imports[name] = imported
+	return nil
+}
+
+// resolve finds the file an import of name from the module at from refers
+// to, first next to the importing file then in the search path. The `.css`
+// extension can be left out.
+func (l *Loader) resolve(from string, name string) (string, error) {
+	candidates := []string{name}
+	if filepath.Ext(name) == "" {
+		candidates = append(candidates, name+".css")
+	}
+
+	dirs := []string{filepath.Dir(from)}
+	if filepath.IsAbs(name) {
+		dirs = []string{""}
+	} else {
+		dirs = append(dirs, l.SearchPath...)
+	}
+
+	for _, dir := range dirs {
+		for _, candidate := range candidates {
+			path := filepath.Join(dir, candidate)
+			stat, err := os.Stat(path)
+			if err == nil && !stat.IsDir() {
+				return path, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("cannot find module %q", name)
+}
+
+func (l *Loader) searchPathString() string {
+	if len(l.SearchPath) == 0 {
+		return "no other directory"
+	}
+	return strings.Join(l.SearchPath, ", ")
+}
+
+// relative shortens an absolute path for messages when it is inside the
+// working directory.
+func (l *Loader) relative(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,72 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.css": `@use "a.css" as a; @import "b.css"; main { }`,
+		"a.css":    `@use "b.css" as b; f { }`,
+		"b.css":    `g { }`,
+	})
+
+	mod, diags, err := New().Load(filepath.Join(dir, "main.css"))
+	if err != nil || len(diags) > 0 {
+		t.Fatalf("failed to load the program: %v %v", err, diags)
+	}
+	if len(mod.Imports) != 2 {
+		t.Fatalf("got %d imports, want 2", len(mod.Imports))
+	}
+	if mod.Imports["b.css"] != mod.Imports["a.css"].Imports["b.css"] {
+		t.Error("a module imported twice is loaded twice")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"cycle", map[string]string{"main.css": `@use "a.css" as a;`, "a.css": `@use "main.css" as m;`}, "import cycle: "},
+		{"missing module", map[string]string{"main.css": `@use "nope.css" as n;`}, `cannot find module "nope.css"`},
+		{"path is a string", map[string]string{"main.css": `@use nope;`}, "the path of a module must be a string"},
+		{"syntax errors in imports", map[string]string{"main.css": `@use "a.css" as a;`, "a.css": `f { --x: 1 + ; }`}, "Expected a value but got SEMICOLON"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := writeFiles(t, test.files)
+			_, diags, err := New().Load(filepath.Join(dir, "main.css"))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(diags) != 1 || !strings.Contains(diags[0].Message, test.want) {
+				t.Errorf("got %v, want a diagnostic containing %q", diags, test.want)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, _, err := New().Load(filepath.Join(t.TempDir(), "main.css"))
+		if err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
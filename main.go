@@ -3,13 +3,28 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/shreyassanthu77/cisp/checker"
+	"github.com/shreyassanthu77/cisp/diagnostic"
 	"github.com/shreyassanthu77/cisp/interpreter"
-	"github.com/shreyassanthu77/cisp/lexer"
-	"github.com/shreyassanthu77/cisp/parser"
+	"github.com/shreyassanthu77/cisp/loader"
 )
 
+// useColor reports whether stdout is a terminal that should get colored
+// output, honoring the NO_COLOR convention.
+func useColor() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	stat, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
 func main() {
 	args := os.Args[1:]
 	if len(args) == 0 {
@@ -17,30 +32,42 @@ func main() {
 		return
 	}
 
+	color := useColor()
+	searchPath := filepath.SplitList(os.Getenv("CRAP_PATH"))
 	for _, arg := range args {
-		file, err := os.ReadFile(arg)
+		ld := loader.New(searchPath...)
+		mod, diags, err := ld.Load(arg)
 		if err != nil {
 			fmt.Printf("Error loading file: %s\n", err)
 			continue
 		}
-		input := string(file)
 		fmt.Println(">> Executing:", arg)
 		fmt.Println("-------------------------")
 
-		lex := lexer.New(input)
-		par := parser.New(lex)
+		renderer := diagnostic.Renderer{
+			File:    arg,
+			Source:  mod.Source,
+			Sources: ld.Sources(),
+			Color:   color,
+		}
 
-		ast, err := par.Parse()
-		if err != nil {
-			fmt.Println(err)
+		if !diagnostic.HasErrors(diags) {
+			for _, m := range ld.Modules() {
+				diags = append(diags, checker.Check(m.Program)...)
+			}
+		}
+		for _, diag := range diags {
+			fmt.Println(renderer.Render(diag))
+		}
+		if diagnostic.HasErrors(diags) {
 			return
 		}
 
 		t := time.Now()
-		res, err := interpreter.Eval(ast)
+		res, err := interpreter.EvalModule(mod)
 		done := time.Since(t)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Println(renderer.RenderError(err))
 			return
 		}
 
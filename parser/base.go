@@ -1,38 +1,48 @@
 package parser
 
 import (
-	"fmt"
-
 	. "github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/diagnostic"
 	"github.com/shreyassanthu77/cisp/lexer"
 )
 
 type Parser struct {
-	lex    *lexer.Lexer
-	tok    lexer.Token
-	hasTok bool
+	lex *lexer.Lexer
+	// ahead holds the tokens that were peeked but not consumed yet
+	ahead       []lexer.Token
+	diagnostics []diagnostic.Diagnostic
 }
 
 func New(lex *lexer.Lexer) *Parser {
 	return &Parser{
-		lex:    lex,
-		hasTok: false,
+		lex: lex,
 	}
 }
 
+func (p *Parser) error(span lexer.Span, format string, args ...interface{}) error {
+	return diagnostic.Errorf(span, format, args...)
+}
+
+// report records err as a diagnostic so parsing can carry on.
+func (p *Parser) report(err error) {
+	p.diagnostics = append(p.diagnostics, diagnostic.FromError(err))
+}
+
 func (p *Parser) peek() (lexer.Token, error) {
-	if p.hasTok {
-		return p.tok, nil
-	}
+	return p.peekAt(0)
+}
 
-	tok, err := p.lex.Next()
-	if err != nil {
-		return lexer.Token{}, err
+// peekAt returns the token n tokens after the next one without consuming
+// anything, peekAt(0) is peek.
+func (p *Parser) peekAt(n int) (lexer.Token, error) {
+	for len(p.ahead) <= n {
+		tok, err := p.lex.Next()
+		if err != nil {
+			return lexer.Token{}, err
+		}
+		p.ahead = append(p.ahead, tok)
 	}
-
-	p.tok = tok
-	p.hasTok = true
-	return tok, nil
+	return p.ahead[n], nil
 }
 
 func (p *Parser) next() (lexer.Token, error) {
@@ -40,67 +50,118 @@ func (p *Parser) next() (lexer.Token, error) {
 	if err != nil {
 		return lexer.Token{}, err
 	}
-	p.hasTok = false
+	p.ahead = p.ahead[1:]
 	return tok, nil
 }
 
+// expect consumes the next token if it is of the given type. A mismatched
+// token is left in place so that error recovery can resync on it.
 func (p *Parser) expect(typ string) (lexer.Token, error) {
-	tok, err := p.next()
+	tok, err := p.peek()
 	if err != nil {
 		return lexer.Token{}, err
 	}
 	if tok.Typ != typ {
-		return lexer.Token{}, fmt.Errorf("%d:%d Expected %s but got %s", tok.Span.Start.Line, tok.Span.Start.Col, typ, tok.Typ)
+		return lexer.Token{}, p.error(tok.Span, "Expected %s but got %s", typ, tok.Typ)
 	}
+	p.next()
 	return tok, nil
 }
 
-func (p *Parser) Parse() (Program, error) {
-	rules := []IRule{}
-	var lastErr error
-outer:
+// synchronize skips tokens until the parser is at a point where it can
+// resume: just past a `;` or a balanced `{ ... }` block, or in front of a
+// `}` that closes the enclosing block.
+func (p *Parser) synchronize() {
+	depth := 0
 	for {
-		next, err := p.peek()
+		tok, err := p.peek()
+		if err != nil {
+			p.report(err)
+			continue
+		}
 
-		// Skip semicolons
-		for {
-			if err != nil {
-				return Program{}, err
+		switch tok.Typ {
+		case lexer.EOF:
+			return
+		case lexer.TOK_SEMICOLON:
+			p.next()
+			if depth == 0 {
+				return
 			}
-
-			if next.Typ == lexer.EOF {
-				break outer
+		case lexer.TOK_LSQUIRLY:
+			p.next()
+			depth++
+		case lexer.TOK_RSQUIRLY:
+			if depth == 0 {
+				return
 			}
-
-			if next.Typ == lexer.TOK_SEMICOLON {
-				p.next()
-				next, err = p.peek()
-				continue
+			p.next()
+			depth--
+			if depth == 0 {
+				return
 			}
+		default:
+			p.next()
+		}
+	}
+}
+
+// Parse parses the whole program. It does not stop at the first syntax error,
+// every error found is returned as a diagnostic alongside whatever could be
+// parsed.
+func (p *Parser) Parse() (Program, []diagnostic.Diagnostic) {
+	rules := []IRule{}
+	for {
+		next, err := p.peek()
+		if err != nil {
+			p.report(err)
+			continue
+		}
+
+		if next.Typ == lexer.EOF {
 			break
 		}
 
+		// Skip semicolons
+		if next.Typ == lexer.TOK_SEMICOLON {
+			p.next()
+			continue
+		}
+
+		if next.Typ == lexer.TOK_RSQUIRLY {
+			p.next()
+			p.report(p.error(next.Span, "Unexpected `}` without a matching `{`"))
+			continue
+		}
+
 		if next.Typ == lexer.TOK_AT {
 			atRule, err := p.parseAtRule()
 			if err != nil {
-				lastErr = err
-				break
+				p.report(err)
+				p.synchronize()
+				continue
 			}
 			rules = append(rules, atRule)
+		} else if next.Typ == lexer.TOK_IDENTIFIER && isVariable(next.Value) {
+			decl, err := p.parseGlobalDeclaration()
+			if err != nil {
+				p.report(err)
+				p.synchronize()
+				continue
+			}
+			rules = append(rules, decl)
 		} else {
 			rule, err := p.parseRule()
 			if err != nil {
-				lastErr = err
-				break
+				p.report(err)
+				p.synchronize()
+				continue
 			}
 			rules = append(rules, rule)
 		}
 	}
 
-	if lastErr != nil {
-		return Program{}, lastErr
-	}
 	return Program{
 		Rules: rules,
-	}, nil
+	}, p.diagnostics
 }
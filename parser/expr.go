@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"fmt"
 	"strconv"
 
 	. "github.com/shreyassanthu77/cisp/ast"
@@ -9,11 +8,24 @@ import (
 )
 
 func (p *Parser) parseLiteralVal() (Value, error) {
-	tok, err := p.next()
+	tok, err := p.peek()
 	if err != nil {
 		return nil, err
 	}
 
+	// Leave block and statement terminators in place for error recovery
+	if tok.Typ == lexer.TOK_LSQUIRLY ||
+		tok.Typ == lexer.TOK_RSQUIRLY ||
+		tok.Typ == lexer.TOK_SEMICOLON ||
+		tok.Typ == lexer.EOF {
+		return nil, p.error(tok.Span, "Expected a value but got %s", tok.Typ)
+	}
+
+	if tok.Typ == lexer.TOK_AT {
+		return p.parseMatchValue()
+	}
+	p.next()
+
 	switch tok.Typ {
 	case lexer.TOK_IDENTIFIER:
 		next, err := p.peek()
@@ -23,19 +35,33 @@ func (p *Parser) parseLiteralVal() (Value, error) {
 		if next.Typ == lexer.TOK_LPAREN {
 			return p.parseFunctionCall(tok)
 		}
+		// `()` is lexed as a single token, here it's a call without arguments
+		if next.Typ == lexer.TOK_EMPTY {
+			empty, _ := p.next()
+			span := tok.Span
+			span.End = empty.Span.End
+			return FunctionCall{
+				Fn:         Identifier{Name: tok.Value, Span: tok.Span},
+				Parameters: []Value{},
+				Span:       span,
+			}, nil
+		}
 		return Identifier{Name: tok.Value, Span: tok.Span}, nil
 	case lexer.TOK_STRING:
+		if len(tok.Parts) > 0 {
+			return p.parseInterpolatedString(tok)
+		}
 		return String{Value: tok.Value, Span: tok.Span}, nil
 	case lexer.TOK_INT:
 		f, err := strconv.ParseInt(tok.Value, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to parse number: %s", err)
+			return nil, p.error(tok.Span, "Failed to parse number: %s", err)
 		}
 		return Int{Value: f, Span: tok.Span}, nil
 	case lexer.TOK_FLOAT:
 		f, err := strconv.ParseFloat(tok.Value, 64)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to parse number: %s", err)
+			return nil, p.error(tok.Span, "Failed to parse number: %s", err)
 		}
 		return Float{Value: f, Span: tok.Span}, nil
 	case lexer.TOK_TRUE:
@@ -50,18 +76,205 @@ func (p *Parser) parseLiteralVal() (Value, error) {
 			return nil, err
 		}
 
+		next, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if next.Typ == lexer.TOK_COMMA {
+			return p.parseList(tok, []Value{val}, lexer.TOK_RPAREN)
+		}
+		if next.Typ == lexer.TOK_COLON {
+			return p.parseMap(tok, val)
+		}
+
 		_, err = p.expect(lexer.TOK_RPAREN)
 		if err != nil {
 			return nil, err
 		}
 
 		return val, nil
+	case lexer.TOK_LBRACKET:
+		return p.parseList(tok, []Value{}, lexer.TOK_RBRACKET)
+	case lexer.TOK_AMPERSAND:
+		return p.parseAnonymousRule(tok)
 	}
 
-	return nil, fmt.Errorf("Unexpected token: %s", tok.Typ)
+	return nil, p.error(tok.Span, "Unexpected token: %s", tok.Typ)
 }
 
-func (p *Parser) parseUnaryExpression() (Value, error) {
+// parseInterpolatedString parses the expressions inside the `#{}` of a string
+// with a parser of their own, they must each be a single value.
+func (p *Parser) parseInterpolatedString(tok lexer.Token) (InterpolatedString, error) {
+	parts := []Value{}
+	for _, part := range tok.Parts {
+		if part.Expr == nil {
+			if part.Text != "" {
+				parts = append(parts, String{Value: part.Text, Span: tok.Span})
+			}
+			continue
+		}
+
+		sub := New(p.lex.Sub(*part.Expr))
+		value, err := sub.parseValue()
+		if err != nil {
+			return InterpolatedString{}, err
+		}
+
+		next, err := sub.peek()
+		if err != nil {
+			return InterpolatedString{}, err
+		}
+		if next.Typ != lexer.EOF {
+			return InterpolatedString{}, p.error(next.Span, "Expected `}` to end the interpolation but got %s", next.Typ)
+		}
+		parts = append(parts, value)
+	}
+
+	return InterpolatedString{
+		Parts: parts,
+		Span:  tok.Span,
+	}, nil
+}
+
+// parseMatchValue parses a `@match` rule in a value position, no other
+// at-rule can be used as a value.
+func (p *Parser) parseMatchValue() (Match, error) {
+	rule, err := p.parseAtRule()
+	if err != nil {
+		return Match{}, err
+	}
+
+	if rule.Name != "match" {
+		return Match{}, p.error(rule.Span, "@%s can't be used as a value, only @match can", rule.Name)
+	}
+
+	if rule.Body == nil {
+		return Match{}, p.error(rule.Span, "@match needs a block of @case rules")
+	}
+
+	return Match{
+		Rule: rule,
+		Span: rule.Span,
+	}, nil
+}
+
+// parseList parses the rest of a list literal after its opening token.
+// Items of `(1, 2, 3)` are separated by commas while `[1 2 3]` allows both
+// commas and spaces, a trailing comma is fine in either.
+func (p *Parser) parseList(open lexer.Token, values []Value, closing string) (List, error) {
+	needsComma := closing == lexer.TOK_RPAREN
+	separated := len(values) == 0
+	for {
+		next, err := p.peek()
+		if err != nil {
+			return List{}, err
+		}
+
+		if next.Typ == closing {
+			break
+		}
+
+		if next.Typ == lexer.TOK_COMMA {
+			p.next()
+			separated = true
+			continue
+		}
+
+		if needsComma && !separated {
+			return List{}, p.error(next.Span, "Expected COMMA or %s in list but got %s", closing, next.Typ)
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return List{}, err
+		}
+		values = append(values, value)
+		separated = false
+	}
+
+	end, err := p.expect(closing)
+	if err != nil {
+		return List{}, err
+	}
+
+	span := open.Span
+	span.End = end.Span.End
+
+	return List{
+		Values: values,
+		Span:   span,
+	}, nil
+}
+
+// mapKey turns the bare identifiers used as keys in `(key: value)` into
+// strings, any other value is used as is.
+func mapKey(key Value) Value {
+	if id, ok := key.(Identifier); ok {
+		return String{Value: id.Name, Span: id.Span}
+	}
+	return key
+}
+
+// parseMap parses the rest of a `(key: value, other: 2)` literal after its
+// first key.
+func (p *Parser) parseMap(open lexer.Token, key Value) (Map, error) {
+	entries := []MapEntry{}
+	for {
+		_, err := p.expect(lexer.TOK_COLON)
+		if err != nil {
+			return Map{}, err
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return Map{}, err
+		}
+
+		entries = append(entries, MapEntry{
+			Key:   mapKey(key),
+			Value: value,
+		})
+
+		next, err := p.peek()
+		if err != nil {
+			return Map{}, err
+		}
+		if next.Typ != lexer.TOK_COMMA {
+			break
+		}
+		p.next() // Consume ','
+
+		next, err = p.peek()
+		if err != nil {
+			return Map{}, err
+		}
+		if next.Typ == lexer.TOK_RPAREN {
+			break
+		}
+
+		key, err = p.parseValue()
+		if err != nil {
+			return Map{}, err
+		}
+	}
+
+	end, err := p.expect(lexer.TOK_RPAREN)
+	if err != nil {
+		return Map{}, err
+	}
+
+	span := open.Span
+	span.End = end.Span.End
+
+	return Map{
+		Entries: entries,
+		Span:    span,
+	}, nil
+}
+
+// parsePrimaryExpr parses a variable or a literal, the operands that bind
+// tighter than any operator.
+func (p *Parser) parsePrimaryExpr() (Value, error) {
 	next, err := p.peek()
 	if err != nil {
 		return nil, err
@@ -77,18 +290,64 @@ func (p *Parser) parseUnaryExpression() (Value, error) {
 			Name: ident.Value,
 			Span: ident.Span,
 		}
+		span := next.Span
+		span.End = ident.Span.End
 		return VarianleDerefValue{
 			Variable: variable,
-			Span:     ident.Span,
+			Span:     span,
 		}, nil
 	}
 
+	return p.parseLiteralVal()
+}
+
+// parsePowerExpr parses `^`, it is right associative and binds tighter than
+// the unary operators so `-2 ^ 2` is `-(2 ^ 2)` but `2 ^ -1` still works.
+func (p *Parser) parsePowerExpr() (Value, error) {
+	left, err := p.parsePrimaryExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	if next.Typ != lexer.TOK_CARET {
+		return left, nil
+	}
+
+	p.next() // Consume the operator
+
+	right, err := p.parseUnaryExpression()
+	if err != nil {
+		return BinaryOp{}, err
+	}
+
+	span := left.GetSpan()
+	span.End = right.GetSpan().End
+
+	return BinaryOp{
+		Left:  left,
+		Op:    next.Value,
+		Right: right,
+		Span:  span,
+	}, nil
+}
+
+func (p *Parser) parseUnaryExpression() (Value, error) {
+	next, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+
 	if next.Typ == lexer.TOK_BANG ||
 		next.Typ == lexer.TOK_MINUS ||
 		next.Typ == lexer.TOK_PLUS ||
 		next.Typ == lexer.TOK_TILDE {
 		p.next() // Consume the operator
-		val, err := p.parseLiteralVal()
+		val, err := p.parseUnaryExpression()
 		if err != nil {
 			return nil, err
 		}
@@ -101,7 +360,7 @@ func (p *Parser) parseUnaryExpression() (Value, error) {
 		}, nil
 	}
 
-	return p.parseLiteralVal()
+	return p.parsePowerExpr()
 }
 
 func (p *Parser) parseMultiplicativeExpr() (Value, error) {
@@ -116,7 +375,7 @@ func (p *Parser) parseMultiplicativeExpr() (Value, error) {
 			return BinaryOp{}, err
 		}
 
-		if next.Typ != lexer.TOK_ASTERISK && next.Typ != lexer.TOK_SLASH {
+		if next.Typ != lexer.TOK_ASTERISK && next.Typ != lexer.TOK_SLASH && next.Typ != lexer.TOK_PERCENT {
 			break
 		}
 
@@ -178,12 +437,145 @@ func (p *Parser) parseAdditiveExpr() (Value, error) {
 	return left, nil
 }
 
-func (p *Parser) parseRelationalExpr() (Value, error) {
+func (p *Parser) parseShiftExpr() (Value, error) {
 	left, err := p.parseAdditiveExpr()
 	if err != nil {
 		return BinaryOp{}, err
 	}
 
+	for {
+		next, err := p.peek()
+		if err != nil {
+			return BinaryOp{}, err
+		}
+
+		if next.Typ != lexer.TOK_SHIFT_LEFT && next.Typ != lexer.TOK_SHIFT_RIGHT {
+			break
+		}
+
+		p.next() // Consume the operator
+
+		right, err := p.parseAdditiveExpr()
+		if err != nil {
+			return BinaryOp{}, err
+		}
+
+		span := left.GetSpan()
+		span.End = right.GetSpan().End
+
+		left = BinaryOp{
+			Left:  left,
+			Op:    next.Value,
+			Right: right,
+			Span:  span,
+		}
+	}
+
+	return left, nil
+}
+
+func (p *Parser) parseBitwiseAndExpr() (Value, error) {
+	left, err := p.parseShiftExpr()
+	if err != nil {
+		return BinaryOp{}, err
+	}
+
+	for {
+		next, err := p.peek()
+		if err != nil {
+			return BinaryOp{}, err
+		}
+
+		if next.Typ != lexer.TOK_AMPERSAND {
+			break
+		}
+		isAnd, err := p.isBitwiseAnd()
+		if err != nil {
+			return BinaryOp{}, err
+		}
+		if !isAnd {
+			break
+		}
+
+		p.next() // Consume the operator
+
+		right, err := p.parseShiftExpr()
+		if err != nil {
+			return BinaryOp{}, err
+		}
+
+		span := left.GetSpan()
+		span.End = right.GetSpan().End
+
+		left = BinaryOp{
+			Left:  left,
+			Op:    next.Value,
+			Right: right,
+			Span:  span,
+		}
+	}
+
+	return left, nil
+}
+
+func (p *Parser) parseBitwiseOrExpr() (Value, error) {
+	left, err := p.parseBitwiseAndExpr()
+	if err != nil {
+		return BinaryOp{}, err
+	}
+
+	for {
+		next, err := p.peek()
+		if err != nil {
+			return BinaryOp{}, err
+		}
+
+		if next.Typ != lexer.TOK_PIPE {
+			break
+		}
+
+		p.next() // Consume the operator
+
+		right, err := p.parseBitwiseAndExpr()
+		if err != nil {
+			return BinaryOp{}, err
+		}
+
+		span := left.GetSpan()
+		span.End = right.GetSpan().End
+
+		left = BinaryOp{
+			Left:  left,
+			Op:    next.Value,
+			Right: right,
+			Span:  span,
+		}
+	}
+
+	return left, nil
+}
+
+// isBitwiseAnd reports whether the `&` about to be read is the bitwise and
+// operator. An `&` followed by `[`, `{` or a pseudo class starts an anonymous
+// rule instead, `$a &[x] { }` passes a rule along with $a.
+func (p *Parser) isBitwiseAnd() (bool, error) {
+	after, err := p.peekAt(1)
+	if err != nil {
+		return false, err
+	}
+	switch after.Typ {
+	case lexer.TOK_LBRACKET, lexer.TOK_LSQUIRLY, lexer.TOK_COLON:
+		return false, nil
+	}
+	return true, nil
+}
+
+func (p *Parser) parseRelationalExpr() (Value, error) {
+	left, err := p.parseBitwiseOrExpr()
+	if err != nil {
+		return BinaryOp{}, err
+	}
+
 	for {
 		next, err := p.peek()
 		if err != nil {
@@ -197,7 +589,7 @@ func (p *Parser) parseRelationalExpr() (Value, error) {
 
 		p.next() // Consume the operator
 
-		right, err := p.parseAdditiveExpr()
+		right, err := p.parseBitwiseOrExpr()
 		if err != nil {
 			return BinaryOp{}, err
 		}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	. "github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/diagnostic"
 	"github.com/shreyassanthu77/cisp/lexer"
 )
 
@@ -83,7 +84,10 @@ func (p *Parser) parseDeclarationStmt(id Identifier) (Declaration, error) {
 			return Declaration{}, err
 		}
 
-		if next.Typ == lexer.TOK_SEMICOLON {
+		if next.Typ == lexer.TOK_SEMICOLON ||
+			next.Typ == lexer.TOK_IMPORTANT ||
+			next.Typ == lexer.TOK_RSQUIRLY ||
+			next.Typ == lexer.EOF {
 			break
 		}
 
@@ -95,6 +99,16 @@ func (p *Parser) parseDeclarationStmt(id Identifier) (Declaration, error) {
 		values = append(values, value)
 	}
 
+	important := false
+	next, err := p.peek()
+	if err != nil {
+		return Declaration{}, err
+	}
+	if next.Typ == lexer.TOK_IMPORTANT {
+		p.next()
+		important = true
+	}
+
 	semi, err := p.expect(lexer.TOK_SEMICOLON)
 	if err != nil {
 		return Declaration{}, err
@@ -105,6 +119,7 @@ func (p *Parser) parseDeclarationStmt(id Identifier) (Declaration, error) {
 	return Declaration{
 		Property:   id,
 		Parameters: values,
+		Important:  important,
 		Span:       span,
 	}, nil
 }
@@ -150,30 +165,32 @@ func (p *Parser) parseDeclarationBlock() ([]Statement, lexer.Span, error) {
 	span := lsq.Span
 
 	stmts := []Statement{}
-	var lastErr error
 	for {
 		next, err := p.peek()
 		if err != nil {
-			return nil, lexer.Span{}, err
+			p.report(err)
+			continue
 		}
 
 		if next.Typ == lexer.TOK_RSQUIRLY {
 			break
 		}
 
+		if next.Typ == lexer.EOF {
+			diag := diagnostic.Errorf(next.Span, "Unexpected EOF, expected `}`")
+			return nil, lexer.Span{}, diag.WithNote("block opened at %d:%d", lsq.Span.Start.Line, lsq.Span.Start.Col)
+		}
+
 		stmt, err := p.parseStatement()
 		if err != nil {
-			lastErr = err
-			break
+			p.report(err)
+			p.synchronize()
+			continue
 		}
 
 		stmts = append(stmts, stmt)
 	}
 
-	if lastErr != nil {
-		return nil, lexer.Span{}, lastErr
-	}
-
 	rsq, err := p.expect(lexer.TOK_RSQUIRLY)
 	if err != nil {
 		return nil, lexer.Span{}, err
@@ -239,6 +256,37 @@ func (p *Parser) parseAtRule() (AtRule, error) {
 
 	span.End = name.Span.End
 
+	if name.Value == "type" || name.Value == "enum" {
+		return p.parseSelectorAtRule(name, span)
+	}
+
+	if name.Value == "const" {
+		return p.parseConstAtRule(name, span)
+	}
+
+	// `@catch [e] { }` binds the error like an attribute binds a parameter
+	var selector *Selector
+	next, err := p.peek()
+	if err != nil {
+		return AtRule{}, err
+	}
+	if name.Value == "catch" && next.Typ == lexer.TOK_LBRACKET {
+		attrs, err := p.parseAttributes()
+		if err != nil {
+			return AtRule{}, err
+		}
+
+		span.End = attrs[len(attrs)-1].Span.End
+		selector = &Selector{
+			Identifier: Identifier{
+				Name: name.Value,
+				Span: name.Span,
+			},
+			Atrributes: attrs,
+			Span:       span,
+		}
+	}
+
 	params := []Value{}
 	for {
 		next, err := p.peek()
@@ -246,10 +294,26 @@ func (p *Parser) parseAtRule() (AtRule, error) {
 			return AtRule{}, err
 		}
 
-		if next.Typ == lexer.TOK_LSQUIRLY || next.Typ == lexer.TOK_SEMICOLON {
+		if next.Typ == lexer.TOK_LSQUIRLY ||
+			next.Typ == lexer.TOK_SEMICOLON ||
+			next.Typ == lexer.TOK_RSQUIRLY ||
+			next.Typ == lexer.EOF {
 			break
 		}
 
+		// Commas only separate parameters, `@each $k, $v in $map`
+		if next.Typ == lexer.TOK_COMMA {
+			p.next()
+			continue
+		}
+
+		// Keywords are never calls, `@each $x in (a: 1)` doesn't call `in`
+		if next.Typ == lexer.TOK_IDENTIFIER && isAtRuleKeyword(name.Value, next.Value) {
+			p.next()
+			params = append(params, Identifier{Name: next.Value, Span: next.Span})
+			continue
+		}
+
 		param, err := p.parseValue()
 		if err != nil {
 			return AtRule{}, err
@@ -262,7 +326,7 @@ func (p *Parser) parseAtRule() (AtRule, error) {
 		span.End = params[len(params)-1].GetSpan().End
 	}
 
-	next, err := p.peek()
+	next, err = p.peek()
 	if err != nil {
 		return AtRule{}, err
 	}
@@ -278,6 +342,7 @@ func (p *Parser) parseAtRule() (AtRule, error) {
 		return AtRule{
 			Name:       name.Value,
 			Parameters: params,
+			Selector:   selector,
 			Body:       decls,
 			Span:       span,
 		}, nil
@@ -294,3 +359,95 @@ func (p *Parser) parseAtRule() (AtRule, error) {
 		Span:       span,
 	}, nil
 }
+
+// parseSelectorAtRule parses the rest of an at-rule whose prelude is a
+// selector, such as `@type point[x][y=0];` or `@enum direction[north][south];`
+func (p *Parser) parseSelectorAtRule(name lexer.Token, span lexer.Span) (AtRule, error) {
+	id, err := p.expect(lexer.TOK_IDENTIFIER)
+	if err != nil {
+		return AtRule{}, err
+	}
+
+	selector, err := p.parseSelector(Identifier{
+		Name: id.Value,
+		Span: id.Span,
+	})
+	if err != nil {
+		return AtRule{}, err
+	}
+
+	semi, err := p.expect(lexer.TOK_SEMICOLON)
+	if err != nil {
+		return AtRule{}, err
+	}
+
+	span.End = semi.Span.End
+
+	return AtRule{
+		Name:     name.Value,
+		Selector: &selector,
+		Span:     span,
+	}, nil
+}
+
+// isAtRuleKeyword reports whether word is one of the words that structure
+// the parameters of the at-rule called rule, the `in` of `@each $x in $l`.
+func isAtRuleKeyword(rule string, word string) bool {
+	switch rule {
+	case "for":
+		return word == "from" || word == "through" || word == "to"
+	case "each":
+		return word == "in"
+	case "case":
+		return word == "if"
+	}
+	return false
+}
+
+func isVariable(name string) bool {
+	return len(name) > 2 && name[:2] == "--"
+}
+
+// parseGlobalDeclaration parses a variable declared outside of any rule,
+// anything else at the top level is a rule.
+func (p *Parser) parseGlobalDeclaration() (Declaration, error) {
+	id, err := p.expect(lexer.TOK_IDENTIFIER)
+	if err != nil {
+		return Declaration{}, err
+	}
+
+	return p.parseDeclarationStmt(Identifier{
+		Name: id.Value,
+		Span: id.Span,
+	})
+}
+
+// parseConstAtRule parses `@const --pi: 3.14159;` which declares the same
+// constant as `--pi: 3.14159 !important;`
+func (p *Parser) parseConstAtRule(name lexer.Token, span lexer.Span) (AtRule, error) {
+	id, err := p.expect(lexer.TOK_IDENTIFIER)
+	if err != nil {
+		return AtRule{}, err
+	}
+
+	if !isVariable(id.Value) {
+		return AtRule{}, p.error(id.Span, "Expected a variable like --name after @const but got %s", id.Value)
+	}
+
+	decl, err := p.parseDeclarationStmt(Identifier{
+		Name: id.Value,
+		Span: id.Span,
+	})
+	if err != nil {
+		return AtRule{}, err
+	}
+	decl.Important = true
+
+	span.End = decl.Span.End
+
+	return AtRule{
+		Name: name.Value,
+		Body: []Statement{decl},
+		Span: span,
+	}, nil
+}
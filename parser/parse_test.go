@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/shreyassanthu77/cisp/ast"
+	"github.com/shreyassanthu77/cisp/lexer"
+)
+
+func parse(t *testing.T, src string) Program {
+	t.Helper()
+	program, diags := New(lexer.New(src)).Parse()
+	if len(diags) > 0 {
+		t.Fatalf("unexpected parse error: %s", diags[0].Message)
+	}
+	return program
+}
+
+// atRule returns the first statement of the body of the first rule of src.
+func atRule(t *testing.T, src string) AtRule {
+	t.Helper()
+	rule := parse(t, src).Rules[0].(Rule)
+	at, ok := rule.Body[0].(AtRule)
+	if !ok {
+		t.Fatalf("got %T, want an at-rule", rule.Body[0])
+	}
+	return at
+}
+
+// kinds describes each parameter of an at-rule as a keyword, a call or
+// another value.
+func kinds(params []Value) []string {
+	res := make([]string, len(params))
+	for i, param := range params {
+		switch param.(type) {
+		case Identifier:
+			res[i] = "keyword"
+		case FunctionCall:
+			res[i] = "call"
+		default:
+			res[i] = "value"
+		}
+	}
+	return res
+}
+
+func TestAtRuleKeywords(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"each", `main { @each $x in (1, 2) { } }`, []string{"value", "keyword", "value"}},
+		{"each over a map", `main { @each $k, $v in (a: 1) { } }`, []string{"value", "value", "keyword", "value"}},
+		{"for", `main { @for $i from 1 through 3 { } }`, []string{"value", "keyword", "value", "keyword", "value"}},
+		{"for to", `main { @for $i from 1 to (3) { } }`, []string{"value", "keyword", "value", "keyword", "value"}},
+		{"return is not a loop", `main { @return to(1); }`, []string{"call"}},
+		{"if is not a case", `main { @if in(1) { } }`, []string{"call"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := kinds(atRule(t, test.src).Parameters)
+			if strings.Join(got, " ") != strings.Join(test.want, " ") {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	t.Run("case guard", func(t *testing.T) {
+		match := atRule(t, `main { @match 1 { @case $x if ($x) { } } }`)
+		got := kinds(match.Body[0].(AtRule).Parameters)
+		want := []string{"value", "keyword", "value"}
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/shreyassanthu77/cisp/lexer"
+)
+
+type wantDiagnostic struct {
+	line    int
+	col     int
+	message string
+}
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		rules int
+		want  []wantDiagnostic
+	}{
+		{
+			name:  "one bad declaration",
+			src:   "a { @return 1; }\nb { --y: 1 + ; }\nc { }",
+			rules: 3,
+			want:  []wantDiagnostic{{2, 14, "Expected a value but got SEMICOLON"}},
+		},
+		{
+			name:  "stray closing brace",
+			src:   "}\nmain { }",
+			rules: 1,
+			want:  []wantDiagnostic{{1, 1, "Unexpected `}` without a matching `{`"}},
+		},
+		{
+			name:  "missing semicolon",
+			src:   "main { --x: 1 }\nother { }",
+			rules: 2,
+			want:  []wantDiagnostic{{1, 15, "Expected SEMICOLON but got RSQUIRLY"}},
+		},
+		{
+			name:  "unclosed list",
+			src:   "main { --x: (1, ; --y: 2; }\nok { }",
+			rules: 2,
+			want:  []wantDiagnostic{{1, 17, "Expected a value but got SEMICOLON"}},
+		},
+		{
+			name:  "every error is reported",
+			src:   "@type;\nmain { --x: 1 + ; }\nother { --y: * 2; }\nlast { print: \"a; }\n",
+			rules: 2,
+			want: []wantDiagnostic{
+				{1, 6, "Expected IDENTIFIER but got SEMICOLON"},
+				{2, 17, "Expected a value but got SEMICOLON"},
+				{3, 14, "Unexpected token: ASTERISK"},
+				{4, 15, "Unterminated string"},
+				{5, 1, "Unexpected EOF, expected `}`"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			program, diags := New(lexer.New(test.src)).Parse()
+			if len(program.Rules) != test.rules {
+				t.Errorf("got %d rules, want %d", len(program.Rules), test.rules)
+			}
+			if len(diags) != len(test.want) {
+				for _, diag := range diags {
+					t.Logf("%d:%d %s", diag.Span.Start.Line, diag.Span.Start.Col, diag.Message)
+				}
+				t.Fatalf("got %d diagnostics, want %d", len(diags), len(test.want))
+			}
+			for i, want := range test.want {
+				got := wantDiagnostic{diags[i].Span.Start.Line, diags[i].Span.Start.Col, diags[i].Message}
+				if got != want {
+					t.Errorf("got %d:%d %s, want %d:%d %s", got.line, got.col, got.message, want.line, want.col, want.message)
+				}
+			}
+		})
+	}
+}
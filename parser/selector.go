@@ -6,43 +6,137 @@ import (
 )
 
 func (p *Parser) parseSelector(ident Identifier) (Selector, error) {
+	span := ident.Span
+	path := []SelectorSegment{}
+	for {
+		next, err := p.peek()
+		if err != nil {
+			return Selector{}, err
+		}
+
+		combinator := COMBINATOR_DESCENDANT
+		if next.Typ == lexer.TOK_GREATER_THAN {
+			p.next() // Consume '>'
+			combinator = COMBINATOR_CHILD
+		} else if next.Typ != lexer.TOK_IDENTIFIER {
+			break
+		}
+
+		id, err := p.expect(lexer.TOK_IDENTIFIER)
+		if err != nil {
+			return Selector{}, err
+		}
+
+		path = append(path, SelectorSegment{
+			Name:       ident,
+			Combinator: combinator,
+		})
+		ident = Identifier{
+			Name: id.Value,
+			Span: id.Span,
+		}
+	}
+	span.End = ident.Span.End
+
+	if len(path) == 0 {
+		path = nil
+	}
+
+	var attrs []Attreibute
 	next, err := p.peek()
 	if err != nil {
 		return Selector{}, err
 	}
 
 	if next.Typ == lexer.TOK_LBRACKET {
-		attr, err := p.parseAttributes()
+		attrs, err = p.parseAttributes()
 		if err != nil {
 			return Selector{}, err
 		}
 
-		span := ident.Span
-		span.End = attr[len(attr)-1].Span.End
-
-		return Selector{
-			Identifier: ident,
-			Atrributes: attr,
-			Span:       span,
-		}, nil
+		span.End = attrs[len(attrs)-1].Span.End
 	}
 
-	next, err = p.peek()
+	pseudoClasses, err := p.parsePseudoClasses()
 	if err != nil {
 		return Selector{}, err
 	}
 
-	if next.Typ == lexer.TOK_IDENTIFIER {
-		panic("Complex selectors not implemented")
+	if len(pseudoClasses) > 0 {
+		span.End = pseudoClasses[len(pseudoClasses)-1].Span.End
 	}
 
 	return Selector{
-		Identifier: ident,
-		Atrributes: nil,
-		Span:       ident.Span,
+		Path:          path,
+		Identifier:    ident,
+		Atrributes:    attrs,
+		PseudoClasses: pseudoClasses,
+		Span:          span,
 	}, nil
 }
 
+func (p *Parser) parsePseudoClasses() ([]PseudoClass, error) {
+	var pseudoClasses []PseudoClass
+
+	for {
+		next, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+
+		if next.Typ != lexer.TOK_COLON {
+			break
+		}
+
+		p.next() // Consume ':'
+		id, err := p.expect(lexer.TOK_IDENTIFIER)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.expect(lexer.TOK_LPAREN)
+		if err != nil {
+			return nil, err
+		}
+
+		arg, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		rparen, err := p.expect(lexer.TOK_RPAREN)
+		if err != nil {
+			return nil, err
+		}
+
+		span := next.Span
+		span.End = rparen.Span.End
+
+		pseudoClasses = append(pseudoClasses, PseudoClass{
+			Name: Identifier{
+				Name: id.Value,
+				Span: id.Span,
+			},
+			Argument: arg,
+			Span:     span,
+		})
+	}
+
+	return pseudoClasses, nil
+}
+
+func isAttributeOperator(typ string) bool {
+	switch typ {
+	case lexer.TOK_PREFIX_MATCH,
+		lexer.TOK_SUFFIX_MATCH,
+		lexer.TOK_SUBSTRING_MATCH,
+		lexer.TOK_DASH_MATCH,
+		lexer.TOK_INCLUDES_MATCH:
+		return true
+	}
+	return false
+}
+
 func (p *Parser) parseAttributes() ([]Attreibute, error) {
 	attrs := []Attreibute{}
 
@@ -78,6 +172,42 @@ func (p *Parser) parseAttributes() ([]Attreibute, error) {
 			Span:    id.Span,
 		}
 
+		if next.Typ == lexer.TOK_COLON {
+			p.next() // Consume ':'
+			typ, err := p.expect(lexer.TOK_IDENTIFIER)
+			if err != nil {
+				return nil, err
+			}
+
+			attr.Type = Identifier{
+				Name: typ.Value,
+				Span: typ.Span,
+			}
+			attr.Span.End = typ.Span.End
+
+			next, err = p.peek()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if isAttributeOperator(next.Typ) {
+			p.next() // Consume the operator
+			operand, err := p.parseLiteralVal()
+			if err != nil {
+				return nil, err
+			}
+
+			attr.Operator = next.Value
+			attr.Operand = operand
+			attr.Span.End = operand.GetSpan().End
+
+			next, err = p.peek()
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		if next.Typ == lexer.TOK_EQUAL {
 			p.next() // Consume '='
 			val, err := p.parseLiteralVal()
@@ -98,3 +228,48 @@ func (p *Parser) parseAttributes() ([]Attreibute, error) {
 
 	return attrs, nil
 }
+
+// parseAnonymousRule parses a function literal after its `&`, the attributes
+// and guards are parsed like those of any other selector.
+func (p *Parser) parseAnonymousRule(amp lexer.Token) (AnonymousRule, error) {
+	span := amp.Span
+
+	attrs, err := p.parseAttributes()
+	if err != nil {
+		return AnonymousRule{}, err
+	}
+
+	pseudoClasses, err := p.parsePseudoClasses()
+	if err != nil {
+		return AnonymousRule{}, err
+	}
+
+	selector := Selector{
+		Identifier: Identifier{
+			Name: amp.Value,
+			Span: amp.Span,
+		},
+		Atrributes:    attrs,
+		PseudoClasses: pseudoClasses,
+		Span:          span,
+	}
+	if len(attrs) > 0 {
+		selector.Span.End = attrs[len(attrs)-1].Span.End
+	}
+	if len(pseudoClasses) > 0 {
+		selector.Span.End = pseudoClasses[len(pseudoClasses)-1].Span.End
+	}
+
+	body, bodySpan, err := p.parseDeclarationBlock()
+	if err != nil {
+		return AnonymousRule{}, err
+	}
+
+	span.End = bodySpan.End
+
+	return AnonymousRule{
+		Selector: selector,
+		Body:     body,
+		Span:     span,
+	}, nil
+}